@@ -0,0 +1,125 @@
+// Package apierror définit le modèle d'erreur de l'API : un catalogue de codes stables et des réponses
+// au format RFC 7807 (application/problem+json)
+package apierror
+
+import (
+	"errors"
+	"to-do-list-api/i18n"
+	"to-do-list-api/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType est le type des réponses d'erreur
+const ContentType = "application/problem+json"
+
+// TypeBaseURI préfixe le code pour former le membre "type" d'une erreur. La référence est relative
+// à l'API, qui décrit chaque code sur GET /problems/{code}
+var TypeBaseURI = "/problems/"
+
+// FieldError décrit l'erreur portant sur un champ de la requête
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// Problem est le corps d'une réponse d'erreur (RFC 7807), complété du code de l'erreur, de l'identifiant
+// de la requête et des erreurs par champ
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Error est une erreur applicative portant son code et la clé i18n de son détail, renvoyée par les fonctions
+// qui ne répondent pas elles-mêmes
+type Error struct {
+	Code      Code
+	DetailKey string
+}
+
+// New crée une erreur applicative
+func New(code Code, detailKey string) *Error {
+	return &Error{Code: code, DetailKey: detailKey}
+}
+
+func (e *Error) Error() string {
+	return string(e.Code) + " : " + i18n.Translate(i18n.Default, e.DetailKey)
+}
+
+// NewProblem construit le corps de l'erreur pour la requête en cours, dont le détail est déjà traduit
+func NewProblem(c *gin.Context, code Code, detail string, fieldErrors ...FieldError) Problem {
+	definition := lookup(code)
+	return Problem{
+		Type:      TypeBaseURI + string(code),
+		Title:     title(c.Request.Context(), code),
+		Status:    definition.Status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: pkg.RequestIDFromContext(c.Request.Context()),
+		Errors:    fieldErrors,
+	}
+}
+
+// Respond envoie une réponse d'erreur, avec le statut HTTP associé au code dans le catalogue. Le détail est le message
+// detailKey des catalogues i18n, traduit dans la langue de la requête
+func Respond(c *gin.Context, code Code, detailKey string, fieldErrors ...FieldError) {
+	send(c, NewProblem(c, code, i18n.T(c.Request.Context(), detailKey), fieldErrors...))
+}
+
+// Respondf envoie une réponse d'erreur dont le détail traduit reçoit des arguments, à la manière de fmt.Sprintf
+func Respondf(c *gin.Context, code Code, detailKey string, args ...any) {
+	send(c, NewProblem(c, code, i18n.T(c.Request.Context(), detailKey, args...)))
+}
+
+// Abort envoie une réponse d'erreur et interrompt la chaîne des middlewares
+func Abort(c *gin.Context, code Code, detailKey string, fieldErrors ...FieldError) {
+	c.Abort()
+	Respond(c, code, detailKey, fieldErrors...)
+}
+
+// Invalid signale une erreur de validation portant sur un seul champ
+func Invalid(c *gin.Context, field, fieldCode, detailKey string) {
+	Respond(c, CodeValidationFailed, detailKey, Field(c, field, fieldCode, detailKey))
+}
+
+// Field construit l'erreur d'un champ, dont le détail est traduit dans la langue de la requête
+func Field(c *gin.Context, field, fieldCode, detailKey string, args ...any) FieldError {
+	return FieldError{Field: field, Code: fieldCode, Detail: i18n.T(c.Request.Context(), detailKey, args...)}
+}
+
+// RespondError envoie la réponse correspondant à err : son code s'il s'agit d'une *Error, une erreur interne sinon
+func RespondError(c *gin.Context, err error) {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		Respond(c, apiErr.Code, apiErr.DetailKey)
+		return
+	}
+	Respond(c, CodeInternal, "internal.server")
+}
+
+// NotFound répond aux requêtes dont la route n'existe pas
+func NotFound(c *gin.Context) {
+	Respondf(c, CodeNotFound, "request.route_not_found", c.Request.Method, c.Request.URL.Path)
+}
+
+// send écrit le corps de l'erreur
+func send(c *gin.Context, problem Problem) {
+	c.Header("Content-Type", ContentType)
+	c.JSON(problem.Status, problem)
+}
+
+// lookup renvoie la définition du code, ou celle de l'erreur interne pour un code inconnu
+func lookup(code Code) Definition {
+	if definition, exists := Catalog[code]; exists {
+		return definition
+	}
+	return Catalog[CodeInternal]
+}
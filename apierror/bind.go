@@ -0,0 +1,85 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	//Les erreurs de validation désignent les champs par leur nom JSON, tel que le client les envoie
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(jsonFieldName)
+	}
+}
+
+// jsonFieldName renvoie le nom JSON (ou de formulaire) d'un champ de structure
+func jsonFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// RespondBindError répond à l'échec de lecture d'une requête : toutes les erreurs de champ ensemble lorsque
+// la validation échoue, invalid_request lorsque le corps est illisible
+func RespondBindError(c *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := make([]FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			fieldErrors = append(fieldErrors, validationError(c, fieldErr))
+		}
+		Respond(c, CodeValidationFailed, "request.invalid_fields", fieldErrors...)
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		Respond(c, CodeValidationFailed, "request.invalid_fields", Field(c, typeErr.Field, "type", "validation.type", typeErr.Type.String()))
+		return
+	}
+
+	Respond(c, CodeInvalidRequest, "request.unreadable")
+}
+
+// Code et message des erreurs de champ pour les règles dont le nom n'est pas parlant pour un client
+var ruleFieldErrors = map[string]struct{ code, detailKey string }{
+	"email":      {"format", "validation.email"},
+	"emailaddr":  {"format", "validation.email"},
+	"username":   {"format", "validation.username"},
+	"title":      {"format", "validation.title"},
+	"taskstatus": {"oneof", "validation.status"},
+	"language":   {"oneof", "validation.language"},
+	"tags":       {"format", "validation.tags"},
+	"tag":        {"format", "validation.tag"},
+}
+
+// validationError décrit une règle de validation non respectée
+func validationError(c *gin.Context, fieldErr validator.FieldError) FieldError {
+	tag := fieldErr.Tag()
+	if rule, found := ruleFieldErrors[tag]; found {
+		return Field(c, fieldErr.Field(), rule.code, rule.detailKey)
+	}
+	switch tag {
+	case "required":
+		return Field(c, fieldErr.Field(), tag, "validation.required")
+	case "min", "max", "oneof":
+		return Field(c, fieldErr.Field(), tag, "validation."+tag, fieldErr.Param())
+	case "password":
+		//Règle de la politique des mots de passe : le paramètre est le code de la raison du rejet
+		return Field(c, fieldErr.Field(), fieldErr.Param(), "password."+fieldErr.Param())
+	}
+	return Field(c, fieldErr.Field(), tag, "validation.other", tag)
+}
@@ -0,0 +1,141 @@
+package apierror
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"to-do-list-api/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code identifie une erreur de façon stable : les clients s'appuient sur lui plutôt que sur le message.
+// Un code publié n'est jamais renommé ni réaffecté
+type Code string
+
+// Definition décrit un code du catalogue. Son titre est le message "problem.<code>" des catalogues i18n
+type Definition struct {
+	Status int `json:"status"`
+}
+
+// Codes d'erreur de l'API
+const (
+	CodeInvalidRequest   Code = "invalid_request"
+	CodeValidationFailed Code = "validation_failed"
+	CodeNotFound         Code = "not_found"
+	CodeInternal         Code = "internal_error"
+
+	CodeUnauthenticated          Code = "unauthenticated"
+	CodeSessionInvalid           Code = "session_invalid"
+	CodeSessionExpired           Code = "session_expired"
+	CodeInvalidCredentials       Code = "invalid_credentials"
+	CodeCurrentPasswordIncorrect Code = "current_password_incorrect"
+	CodeTooManyLoginAttempts     Code = "too_many_login_attempts"
+	CodeAccountDisabled          Code = "account_disabled"
+	CodeCSRFInvalid              Code = "csrf_invalid"
+	CodeAdminRequired            Code = "admin_required"
+	CodeRateLimited              Code = "rate_limited"
+
+	CodeMagicLinkInvalid Code = "magic_link_invalid"
+
+	CodeOIDCProviderUnknown      Code = "oidc_provider_unknown"
+	CodeOIDCProviderUnavailable  Code = "oidc_provider_unavailable"
+	CodeOIDCFlowInvalid          Code = "oidc_flow_invalid"
+	CodeOIDCStateInvalid         Code = "oidc_state_invalid"
+	CodeOIDCDenied               Code = "oidc_denied"
+	CodeOIDCAuthenticationFailed Code = "oidc_authentication_failed"
+	CodeOIDCEmailMissing         Code = "oidc_email_missing"
+	CodeOIDCAccountNotLinked     Code = "oidc_account_not_linked"
+	CodeOIDCEmailTaken           Code = "oidc_email_taken"
+
+	CodeUserNotFound  Code = "user_not_found"
+	CodeUserForbidden Code = "user_forbidden"
+	CodeUsernameTaken Code = "username_taken"
+	CodeEmailTaken    Code = "email_taken"
+	CodeAccountExists Code = "account_exists"
+	CodeTaskNotFound  Code = "task_not_found"
+	CodeTaskForbidden Code = "task_forbidden"
+)
+
+// Catalog liste toutes les erreurs que l'API peut renvoyer
+var Catalog = map[Code]Definition{
+	CodeInvalidRequest:   {Status: http.StatusBadRequest},
+	CodeValidationFailed: {Status: http.StatusBadRequest},
+	CodeNotFound:         {Status: http.StatusNotFound},
+	CodeInternal:         {Status: http.StatusInternalServerError},
+
+	CodeUnauthenticated:          {Status: http.StatusUnauthorized},
+	CodeSessionInvalid:           {Status: http.StatusUnauthorized},
+	CodeSessionExpired:           {Status: http.StatusUnauthorized},
+	CodeInvalidCredentials:       {Status: http.StatusUnauthorized},
+	CodeCurrentPasswordIncorrect: {Status: http.StatusUnauthorized},
+	CodeTooManyLoginAttempts:     {Status: http.StatusTooManyRequests},
+	CodeAccountDisabled:          {Status: http.StatusForbidden},
+	CodeCSRFInvalid:              {Status: http.StatusForbidden},
+	CodeAdminRequired:            {Status: http.StatusForbidden},
+	CodeRateLimited:              {Status: http.StatusTooManyRequests},
+
+	CodeMagicLinkInvalid: {Status: http.StatusUnauthorized},
+
+	CodeOIDCProviderUnknown:      {Status: http.StatusNotFound},
+	CodeOIDCProviderUnavailable:  {Status: http.StatusBadGateway},
+	CodeOIDCFlowInvalid:          {Status: http.StatusBadRequest},
+	CodeOIDCStateInvalid:         {Status: http.StatusBadRequest},
+	CodeOIDCDenied:               {Status: http.StatusUnauthorized},
+	CodeOIDCAuthenticationFailed: {Status: http.StatusUnauthorized},
+	CodeOIDCEmailMissing:         {Status: http.StatusForbidden},
+	CodeOIDCAccountNotLinked:     {Status: http.StatusForbidden},
+	CodeOIDCEmailTaken:           {Status: http.StatusConflict},
+
+	CodeUserNotFound:  {Status: http.StatusNotFound},
+	CodeUserForbidden: {Status: http.StatusForbidden},
+	CodeUsernameTaken: {Status: http.StatusBadRequest},
+	CodeEmailTaken:    {Status: http.StatusBadRequest},
+	CodeAccountExists: {Status: http.StatusBadRequest},
+	CodeTaskNotFound:  {Status: http.StatusNotFound},
+	CodeTaskForbidden: {Status: http.StatusUnauthorized},
+}
+
+// CatalogEntry décrit un code d'erreur (réponse de GET /problems)
+type CatalogEntry struct {
+	Code   Code   `json:"code"`
+	Type   string `json:"type"`
+	Status int    `json:"status"`
+	Title  string `json:"title"`
+}
+
+// entry renvoie la description publiée d'un code, dans la langue de la requête
+func entry(ctx context.Context, code Code, definition Definition) CatalogEntry {
+	return CatalogEntry{Code: code, Type: TypeBaseURI + string(code), Status: definition.Status, Title: title(ctx, code)}
+}
+
+// title renvoie le titre traduit d'un code
+func title(ctx context.Context, code Code) string {
+	return i18n.T(ctx, "problem."+string(code))
+}
+
+// ListProblems renvoie le catalogue des erreurs
+func ListProblems(c *gin.Context) {
+	entries := make([]CatalogEntry, 0, len(Catalog))
+	for code, definition := range Catalog {
+		entries = append(entries, entry(c.Request.Context(), code, definition))
+	}
+	sortEntries(entries)
+	c.JSON(http.StatusOK, gin.H{"problems": entries})
+}
+
+// GetProblem décrit le code d'erreur désigné par le membre "type" d'une réponse d'erreur
+func GetProblem(c *gin.Context) {
+	code := Code(c.Param("code"))
+	definition, exists := Catalog[code]
+	if !exists {
+		Respondf(c, CodeNotFound, "problem.unknown_code", code)
+		return
+	}
+	c.JSON(http.StatusOK, entry(c.Request.Context(), code, definition))
+}
+
+// sortEntries trie le catalogue par code
+func sortEntries(entries []CatalogEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"to-do-list-api/config"
+	"to-do-list-api/models"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
+
+	"golang.org/x/term"
+)
+
+// loadAdminConfig charge la configuration d'une sous-commande d'administration, analyse ses options et applique les
+// réglages dont elle a besoin (journalisation, politique des mots de passe)
+func loadAdminConfig(fs *flag.FlagSet, args []string) *config.Config {
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		pkg.Fatal("configuration invalide", "error", err)
+	}
+	if err := cfg.ApplyLogging(); err != nil {
+		pkg.Fatal("configuration invalide", "error", err)
+	}
+	if err := cfg.ApplyPasswords(); err != nil {
+		pkg.Fatal("configuration invalide", "error", err)
+	}
+	return cfg
+}
+
+// openAdminStore ouvre la base comme le serveur (pkg.InitDatabase : le schéma doit être à jour). L'appelant la ferme
+// avec pkg.CloseDatabase
+func openAdminStore(cfg *config.Config) *store.Store {
+	if pkg.DatabaseDialect(cfg.Database.URL) == pkg.DialectMemory {
+		pkg.Fatal("les dépôts en mémoire ne sont partagés avec aucun serveur : indiquer la base avec -db ou DATABASE_URL")
+	}
+	if err := pkg.InitDatabase(cfg.Database.URL); err != nil {
+		pkg.Fatal("ouverture de la base impossible", "error", err)
+	}
+	return store.NewGormStore(pkg.DB)
+}
+
+// findUser retrouve un compte par identifiant, email ou username
+func findUser(ctx context.Context, s *store.Store, ref string) (*models.User, error) {
+	var user *models.User
+	var err error
+	if id, parseErr := strconv.ParseUint(ref, 10, 32); parseErr == nil {
+		user, err = s.Users.GetUser(ctx, uint(id))
+	} else if strings.Contains(ref, "@") {
+		user, err = s.Users.GetUserByEmail(ctx, pkg.NormalizeEmail(ref))
+	} else {
+		user, err = s.Users.GetUserByUsername(ctx, ref)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("compte %q introuvable", ref)
+	}
+	return user, err
+}
+
+// readPassword lit un mot de passe : sur l'entrée standard avec fromStdin (ou hors terminal), sinon saisi deux fois
+// sans écho
+func readPassword(fromStdin bool) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if fromStdin || !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if line = strings.TrimRight(line, "\r\n"); line == "" {
+			return "", fmt.Errorf("mot de passe absent de l'entrée standard : %v", err)
+		}
+		return line, nil
+	}
+
+	fmt.Fprint(os.Stderr, "Mot de passe : ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(os.Stderr, "Confirmation : ")
+	confirmation, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if string(password) != string(confirmation) {
+		return "", errors.New("les deux saisies diffèrent")
+	}
+	return string(password), nil
+}
+
+// hashNewPassword applique la politique des mots de passe puis hache password
+func hashNewPassword(password string, userInputs ...string) (string, error) {
+	if issues := pkg.CheckPassword(password, userInputs...); len(issues) > 0 {
+		messages := make([]string, len(issues))
+		for i, issue := range issues {
+			messages[i] = issue.Message
+		}
+		return "", fmt.Errorf("mot de passe refusé : %s", strings.Join(messages, " ; "))
+	}
+	return pkg.HashPassword(password)
+}
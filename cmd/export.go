@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"to-do-list-api/dto"
+	"to-do-list-api/migrations"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
+)
+
+const exportUsage = `Utilisation : %s export [options]
+
+Écrit les comptes et les tâches en JSON, dans leur représentation publique (ni mots de passe ni sessions).
+
+Options :
+`
+
+// exportDocument est le document produit par la sous-commande export
+type exportDocument struct {
+	ExportedAt    time.Time          `json:"exported_at"`
+	SchemaVersion uint               `json:"schema_version"`
+	Users         []dto.UserResponse `json:"users"`
+	Tasks         []dto.TaskResponse `json:"tasks"`
+}
+
+// runExport exécute la sous-commande export
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	output := fs.String("output", "", "fichier à écrire (sortie standard par défaut)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), exportUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+	cfg := loadAdminConfig(fs, args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	s := openAdminStore(cfg)
+	defer pkg.CloseDatabase()
+
+	if err := export(context.Background(), s, *output); err != nil {
+		pkg.CloseDatabase()
+		pkg.Fatal("échec de l'export", "error", err)
+	}
+}
+
+// export écrit le document d'export dans le fichier path, ou sur la sortie standard si path est vide
+func export(ctx context.Context, s *store.Store, path string) error {
+	users, err := s.Users.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	tasks, err := s.Tasks.ListTasks(ctx, store.TaskFilter{})
+	if err != nil {
+		return err
+	}
+	document := exportDocument{
+		ExportedAt:    time.Now().UTC(),
+		SchemaVersion: migrations.Latest(),
+		Users:         dto.NewUserResponses(users),
+		Tasks:         dto.NewTaskResponses(tasks, nil),
+	}
+
+	if path == "" {
+		return writeExport(os.Stdout, document)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := writeExport(file, document); err != nil {
+		file.Close()
+		return err
+	}
+	//L'écriture n'est garantie qu'une fois le fichier fermé sans erreur (disque plein, quota...)
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d compte(s) et %d tâche(s) exportés dans %s\n", len(users), len(tasks), path)
+	return nil
+}
+
+// writeExport écrit le document d'export en JSON indenté
+func writeExport(w io.Writer, document exportDocument) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+	"to-do-list-api/jobs"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
+)
+
+const jobsUsage = `Utilisation : %s jobs <action> [options] [<tâche>]
+
+Actions :
+  list          liste les tâches de fond activées, leur planification et leur dernière exécution
+  history       affiche les dernières exécutions (-job, -limit)
+  run <tâche>   exécute immédiatement une tâche ; elle est ignorée si le serveur l'exécute au même moment
+                ou l'a exécutée à son échéance il y a moins d'une minute
+
+Les tâches et leur planification sont celles de la section jobs de la configuration.
+
+Options :
+`
+
+// runJobs exécute la sous-commande jobs
+func runJobs(args []string) {
+	fs := flag.NewFlagSet("jobs", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), jobsUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	action := args[0]
+	var job *string
+	var limit *int
+	if action == "history" {
+		job = fs.String("job", "", "n'afficher que les exécutions de cette tâche")
+		limit = fs.Int("limit", 20, "nombre d'exécutions affichées")
+	}
+	cfg := loadAdminConfig(fs, args[1:])
+	switch {
+	case (action == "list" || action == "history") && fs.NArg() == 0:
+	case action == "run" && fs.NArg() == 1:
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	//Les rappels partent par email, dans la langue par défaut du serveur à défaut de celle de l'utilisateur
+	cfg.ApplyMail()
+	s := openAdminStore(cfg)
+	defer pkg.CloseDatabase()
+	scheduler, err := jobs.New(s, cfg.Jobs.JobOptions())
+	if err != nil {
+		pkg.CloseDatabase()
+		pkg.Fatal("configuration des tâches de fond invalide", "error", err)
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "list":
+		err = printJobs(ctx, s, scheduler)
+	case "history":
+		err = printJobRuns(ctx, s, *job, *limit)
+	case "run":
+		err = runJob(ctx, scheduler, fs.Arg(0))
+	}
+	if err != nil {
+		pkg.CloseDatabase()
+		pkg.Fatal("échec de la commande jobs", "action", action, "error", err)
+	}
+}
+
+// printJobs affiche les tâches enregistrées, leur prochaine et leur dernière exécution
+func printJobs(ctx context.Context, s *store.Store, scheduler *jobs.Scheduler) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TÂCHE\tPLANIFICATION\tPROCHAINE EXÉCUTION\tDERNIÈRE EXÉCUTION")
+	now := time.Now()
+	for _, job := range scheduler.Jobs() {
+		runs, err := s.Jobs.ListJobRuns(ctx, job.Name, 1)
+		if err != nil {
+			return err
+		}
+		last := "jamais"
+		if len(runs) > 0 {
+			last = fmt.Sprintf("%s le %s", runs[0].Status, runs[0].StartedAt.Local().Format(time.DateTime))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", job.Name, job.Schedule, job.Schedule.Next(now).Local().Format(time.DateTime), last)
+	}
+	return w.Flush()
+}
+
+// printJobRuns affiche l'historique des exécutions, de la plus récente à la plus ancienne
+func printJobRuns(ctx context.Context, s *store.Store, job string, limit int) error {
+	runs, err := s.Jobs.ListJobRuns(ctx, job, limit)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTÂCHE\tDÉBUT\tDURÉE\tORIGINE\tINSTANCE\tÉTAT\tÉLÉMENTS\tERREUR")
+	for _, run := range runs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", run.ID, run.Job, run.StartedAt.Local().Format(time.DateTime),
+			run.Duration().Round(time.Millisecond), run.Trigger, run.Instance, run.Status, run.Items, run.Error)
+	}
+	return w.Flush()
+}
+
+// runJob exécute immédiatement une tâche et affiche son résultat
+func runJob(ctx context.Context, scheduler *jobs.Scheduler, name string) error {
+	run, err := scheduler.RunNow(ctx, name)
+	if errors.Is(err, jobs.ErrJobBusy) {
+		fmt.Printf("%s : déjà en cours d'exécution ou tout juste exécutée par le serveur, ignorée\n", name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s : %s, %d élément(s) traité(s) en %s\n", run.Job, run.Status, run.Items, run.Duration().Round(time.Millisecond))
+	return nil
+}
@@ -1,22 +1,146 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"to-do-list-api/config"
+	"to-do-list-api/jobs"
 	"to-do-list-api/pkg"
 	"to-do-list-api/routes"
-	_"to-do-list-api/docs"
+	"to-do-list-api/store"
+	"to-do-list-api/tracing"
+
+	"github.com/gin-gonic/gin"
 )
 
+const usage = `Utilisation : %s [commande] [options]
+
+Commandes :
+  serve           démarre le serveur HTTP (commande par défaut)
+  migrate         gère les migrations du schéma
+  user            crée, liste, désactive ou réactive un compte, réinitialise un mot de passe
+  sessions        purge les sessions
+  seed            crée des comptes et des tâches de démonstration
+  export          exporte les comptes et les tâches en JSON
+  jobs            liste les tâches de fond, affiche leur historique ou en exécute une
+
+Toutes les commandes lisent la même configuration (fichier, variables d'environnement, options) ;
+"%s <commande> -h" détaille chacune d'elles.
+
+Options de serve :
+`
+
+// Sous-commandes du binaire
+var commands = map[string]func(args []string){
+	"migrate":  runMigrate,
+	"user":     runUser,
+	"sessions": runSessions,
+	"seed":     runSeed,
+	"export":   runExport,
+	"jobs":     runJobs,
+}
+
 func main() {
-	// Initialiser la base de données
-	pkg.InitDatabase()
+	//Sans commande, ou avec des options seulement, le serveur démarre comme avant l'introduction des sous-commandes
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "serve" {
+		args = args[1:]
+	} else if len(args) > 0 {
+		if run, found := commands[args[0]]; found {
+			run(args[1:])
+			return
+		}
+	}
+	runServe(args)
+}
+
+// runServe démarre le serveur HTTP
+func runServe(args []string) {
+	//Charger la configuration : fichier, variables d'environnement puis options de ligne de commande
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	printConfig := fs.Bool("print-config", false, "afficher la configuration effective (secrets masqués) et quitter")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), usage, os.Args[0], os.Args[0])
+		fs.PrintDefaults()
+	}
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		pkg.Fatal("configuration invalide", "error", err)
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "commande inconnue : %q\n\n", fs.Arg(0))
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if *printConfig {
+		out, err := cfg.Redacted()
+		if err != nil {
+			pkg.Fatal("affichage de la configuration impossible", "error", err)
+		}
+		fmt.Print(string(out))
+		return
+	}
+
+	if err := cfg.Apply(); err != nil {
+		pkg.Fatal("configuration invalide", "error", err)
+	}
+
+	//Messages de démarrage de gin (routes enregistrées) réservés au niveau debug, sauf GIN_MODE explicite
+	if level, _ := pkg.ParseLogLevel(cfg.Log.Level); os.Getenv(gin.EnvGinMode) == "" && level > slog.LevelDebug {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Initialiser la base de données, ou des dépôts en mémoire avec memory://
+	var s *store.Store
+	var hooks []shutdownHook
+	if pkg.DatabaseDialect(cfg.Database.URL) == pkg.DialectMemory {
+		slog.Warn("dépôts en mémoire : les données ne survivront pas à l'arrêt du serveur")
+		s = store.NewMemoryStore()
+	} else {
+		if err := pkg.InitDatabase(cfg.Database.URL); err != nil {
+			pkg.Fatal("démarrage impossible", "error", err)
+		}
+		s = store.NewGormStore(pkg.DB)
+		hooks = append(hooks, func(context.Context) error {
+			return pkg.CloseDatabase()
+		})
+	}
+
+	//Tâches de fond planifiées, arrêtées avant la fermeture de la base
+	if cfg.Jobs.Enabled {
+		scheduler, err := jobs.New(s, cfg.Jobs.JobOptions())
+		if err != nil {
+			pkg.Fatal("configuration des tâches de fond invalide", "error", err)
+		}
+		scheduler.Start()
+		hooks = append([]shutdownHook{scheduler.Stop}, hooks...)
+	}
+
+	//Export des traces OpenTelemetry ; les spans en attente sont envoyés à l'arrêt
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: cfg.Tracing.ServiceName,
+		Version:     pkg.Version,
+	})
+	if err != nil {
+		pkg.Fatal("initialisation des traces impossible", "error", err)
+	}
+	hooks = append(hooks, shutdownTracing)
 
 	//Configurer le routeur
-	router := routes.SetupRouter()
+	router := routes.SetupRouter(s)
 
-	log.Println("API To-Do List prête à démarrer !")
-	log.Println("Le serveur fonctionne sur http://localhost:8080")
+	slog.Info("API To-Do List prête à démarrer", "addr", cfg.Server.Addr, "version", pkg.Version)
 
-	//Lancer l'application
-	router.Run(":8080")
+	//Lancer l'application jusqu'à SIGINT/SIGTERM
+	if err := serve(cfg.Server, router, hooks...); err != nil {
+		pkg.Fatal("arrêt du serveur en erreur", "error", err)
+	}
 }
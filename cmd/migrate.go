@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+	"to-do-list-api/config"
+	"to-do-list-api/migrations"
+	"to-do-list-api/pkg"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `Utilisation : %s migrate [options] <commande>
+
+Commandes :
+  up            applique toutes les migrations en attente
+  down [n]      annule les n dernières migrations (1 par défaut)
+  to <version>  amène le schéma à la version donnée (0 : base vide)
+  status        affiche l'état de chaque migration
+
+Options :
+`
+
+// runMigrate exécute la sous-commande migrate
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), migrateUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		pkg.Fatal("configuration invalide", "error", err)
+	}
+	if err := cfg.ApplyLogging(); err != nil {
+		pkg.Fatal("configuration invalide", "error", err)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if pkg.DatabaseDialect(cfg.Database.URL) == pkg.DialectMemory {
+		pkg.Fatal("les dépôts en mémoire n'ont pas de schéma à migrer")
+	}
+	db, err := pkg.OpenDatabase(cfg.Database.URL)
+	if err != nil {
+		pkg.Fatal("échec de la connexion à la base de données", "error", err)
+	}
+
+	command, params := fs.Arg(0), fs.Args()[1:]
+	switch {
+	case command == "up" && len(params) == 0:
+		err = migrations.Up(db)
+	case command == "down" && len(params) <= 1:
+		steps := 1
+		if len(params) == 1 {
+			if steps, err = strconv.Atoi(params[0]); err != nil || steps < 1 {
+				pkg.Fatal("nombre de migrations invalide", "value", params[0])
+			}
+		}
+		err = migrations.Down(db, steps)
+	case command == "to" && len(params) == 1:
+		version, parseErr := strconv.ParseUint(params[0], 10, 64)
+		if parseErr != nil {
+			pkg.Fatal("version invalide", "value", params[0])
+		}
+		err = migrations.To(db, uint(version))
+	case command == "status" && len(params) == 0:
+		err = printMigrationStatus(db)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		pkg.Fatal("échec de la commande migrate", "command", command, "error", err)
+	}
+}
+
+// printMigrationStatus affiche l'état des migrations sous forme de tableau
+func printMigrationStatus(db *gorm.DB) error {
+	statuses, err := migrations.Status(db)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNOM\tÉTAT")
+	for _, status := range statuses {
+		state := "en attente"
+		if status.AppliedAt != nil {
+			state = "appliquée le " + status.AppliedAt.Local().Format(time.DateTime)
+		}
+		if status.Unknown {
+			state += " (inconnue de ce binaire)"
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, state)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"to-do-list-api/dto"
+	"to-do-list-api/models"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
+)
+
+const seedUsage = `Utilisation : %s seed [options]
+
+Crée les comptes de démonstration demo1, demo2... (email demoN@example.com) et leurs tâches. Les comptes existants
+sont laissés tels quels : relancer la commande n'ajoute que ceux qui manquent.
+
+Options :
+`
+
+// Titres et étiquettes des tâches de démonstration, attribués à tour de rôle
+var (
+	seedTitles = []string{"Faire les courses", "Préparer la réunion", "Répondre aux emails", "Réviser le budget",
+		"Réserver les billets", "Relire le rapport", "Appeler le plombier", "Mettre à jour le site"}
+	seedTags = [][]string{{"maison"}, {"travail", "urgent"}, {"travail"}, {"finances"}, {"voyage"},
+		{"travail"}, {"maison", "urgent"}, {}}
+)
+
+// runSeed exécute la sous-commande seed
+func runSeed(args []string) {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	users := fs.Int("users", 3, "nombre de comptes de démonstration")
+	tasks := fs.Int("tasks", 5, "nombre de tâches par compte créé")
+	password := fs.String("password", "", "mot de passe des comptes créés (généré s'il est omis)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), seedUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+	cfg := loadAdminConfig(fs, args)
+	if fs.NArg() > 0 || *users < 1 || *tasks < 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	s := openAdminStore(cfg)
+	defer pkg.CloseDatabase()
+
+	if *password == "" {
+		*password = generatePassword()
+	}
+	if err := seed(context.Background(), s, *users, *tasks, *password); err != nil {
+		pkg.CloseDatabase()
+		pkg.Fatal("échec de la commande seed", "error", err)
+	}
+}
+
+// seed crée les comptes manquants et leurs tâches, puis affiche les identifiants de connexion
+func seed(ctx context.Context, s *store.Store, users, tasks int, password string) error {
+	//Hachage unique : la politique s'applique sans les données propres à chaque compte, identiques au préfixe près
+	hash, err := hashNewPassword(password)
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i <= users; i++ {
+		username, email := fmt.Sprintf("demo%d", i), fmt.Sprintf("demo%d@example.com", i)
+		if _, err := s.Users.GetUserByEmail(ctx, email); err == nil {
+			fmt.Printf("%s <%s> : existant, inchangé\n", username, email)
+			continue
+		} else if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+
+		user := &models.User{Username: username, Email: email, Password: hash}
+		if err := s.Users.CreateUser(ctx, user); err != nil {
+			return fmt.Errorf("création de %s : %w", username, err)
+		}
+		for j := 0; j < tasks; j++ {
+			k := (i + j) % len(seedTitles)
+			task := &models.Task{
+				Title:  seedTitles[k],
+				Status: dto.TaskStatuses[(i+j)%len(dto.TaskStatuses)],
+				UserID: user.ID,
+				Tags:   seedTags[k],
+			}
+			if err := s.Tasks.CreateTask(ctx, task); err != nil {
+				return fmt.Errorf("création des tâches de %s : %w", username, err)
+			}
+		}
+		fmt.Printf("%s <%s> : créé avec %d tâche(s), mot de passe %s\n", username, email, tasks, password)
+	}
+	return nil
+}
+
+// generatePassword tire un mot de passe aléatoire qui satisfait la politique (majuscule, minuscule et chiffre garantis)
+func generatePassword() string {
+	secret := make([]byte, 12)
+	if _, err := rand.Read(secret); err != nil {
+		pkg.Fatal("génération du mot de passe impossible", "error", err)
+	}
+	return "Demo-" + base64.RawURLEncoding.EncodeToString(secret) + "-9x"
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"to-do-list-api/config"
+	"to-do-list-api/pkg"
+)
+
+// shutdownHook libère une ressource lors de l'arrêt (tâches de fond, base de données...).
+// Le contexte expire à la fin du délai d'arrêt
+type shutdownHook func(ctx context.Context) error
+
+// serve exécute le serveur HTTP jusqu'à la réception de SIGINT ou SIGTERM, puis l'arrête proprement :
+// plus aucune connexion n'est acceptée, les requêtes en cours sont terminées, puis les hooks sont exécutés
+// dans l'ordre, le tout dans la limite de server.shutdown_timeout. Les hooks sont aussi exécutés si le serveur ne
+// peut pas démarrer
+func serve(cfg config.ServerConfig, handler http.Handler, hooks ...shutdownHook) error {
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		//Le serveur n'a pas démarré (port occupé...) : les hooks libèrent tout de même les ressources déjà acquises
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancel()
+		return errors.Join(append([]error{err}, runHooks(shutdownCtx, hooks)...)...)
+	case <-ctx.Done():
+	}
+
+	//Un second signal interrompt immédiatement le processus
+	stop()
+
+	//Faire échouer /readyz, puis continuer à servir le temps que l'orchestrateur retire l'instance
+	pkg.SetDraining()
+	if cfg.DrainDelay > 0 {
+		slog.Info("arrêt demandé : instance signalée indisponible", "drain_delay", cfg.DrainDelay.String())
+		time.Sleep(cfg.DrainDelay)
+	}
+	slog.Info("arrêt demandé : fin des requêtes en cours", "shutdown_timeout", cfg.ShutdownTimeout.String())
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("arrêt du serveur HTTP : %w", err))
+		srv.Close()
+	}
+	errs = append(errs, runHooks(shutdownCtx, hooks)...)
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	slog.Info("serveur arrêté proprement")
+	return nil
+}
+
+// runHooks exécute les hooks d'arrêt dans l'ordre, même après l'échec de l'un d'eux, et renvoie leurs erreurs
+func runHooks(ctx context.Context, hooks []shutdownHook) []error {
+	var errs []error
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
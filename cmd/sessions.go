@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+	"to-do-list-api/pkg"
+)
+
+const sessionsUsage = `Utilisation : %s sessions purge [options]
+
+Supprime les sessions expirées ; avec -all, toutes les sessions (chaque utilisateur devra se reconnecter).
+
+Options :
+`
+
+// runSessions exécute la sous-commande sessions
+func runSessions(args []string) {
+	fs := flag.NewFlagSet("sessions", flag.ExitOnError)
+	all := fs.Bool("all", false, "supprimer toutes les sessions, y compris celles encore valides")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), sessionsUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != "purge" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	cfg := loadAdminConfig(fs, args[1:])
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	s := openAdminStore(cfg)
+	defer pkg.CloseDatabase()
+
+	var deleted int64
+	var err error
+	if *all {
+		deleted, err = s.Sessions.DeleteAllSessions(context.Background())
+	} else {
+		deleted, err = s.Sessions.DeleteExpiredSessions(context.Background(), time.Now())
+	}
+	if err != nil {
+		pkg.CloseDatabase()
+		pkg.Fatal("échec de la purge des sessions", "error", err)
+	}
+	fmt.Printf("%d session(s) supprimée(s)\n", deleted)
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Préfixe de la version de l'API utilisée par le client
+const apiPrefix = "/v1"
+
+// Client appelle l'API avec le jeton de session d'un profil
+type Client struct {
+	base     string // URL du serveur, préfixe de version compris
+	token    string
+	language string
+	http     *http.Client
+}
+
+// Task est une tâche telle que renvoyée par l'API
+type Task struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	UserID    uint      `json:"user_id"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Session est la session ouverte par une connexion
+type Session struct {
+	Message   string    `json:"message"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// APIError est une erreur renvoyée par l'API (RFC 7807)
+type APIError struct {
+	Status int    `json:"status"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+	Errors []struct {
+		Field  string `json:"field"`
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Detail)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " (%s)", e.Code)
+	}
+	for _, fieldErr := range e.Errors {
+		fmt.Fprintf(&b, "\n  %s : %s", fieldErr.Field, fieldErr.Detail)
+	}
+	switch e.Code {
+	case "unauthenticated", "session_invalid", "session_expired":
+		b.WriteString("\nouvrir une session avec : todo login")
+	}
+	return b.String()
+}
+
+// NewClient crée un client pour le serveur server (ex. http://localhost:8080)
+func NewClient(server, token, language string) (*Client, error) {
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("URL du serveur invalide : %q", server)
+	}
+	return &Client{
+		base:     strings.TrimSuffix(server, "/") + apiPrefix,
+		token:    token,
+		language: language,
+		http:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// do envoie une requête et décode la réponse JSON dans out (si non nil). Les réponses d'erreur deviennent des APIError
+func (c *Client) do(method, path string, query url.Values, body, out any) error {
+	target := c.base + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	request, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", "todo-cli")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.language != "" {
+		request.Header.Set("Accept-Language", c.language)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode >= 400 {
+		apiErr := &APIError{Status: response.StatusCode}
+		if json.Unmarshal(data, apiErr) != nil || apiErr.Detail == "" {
+			apiErr.Detail = fmt.Sprintf("%s %s : %s", method, path, response.Status)
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s : réponse illisible : %w", method, path, err)
+	}
+	return nil
+}
+
+// Login ouvre une session
+func (c *Client) Login(email, password string) (*Session, error) {
+	var session Session
+	err := c.do(http.MethodPost, "/auth/login", nil, map[string]string{"email": email, "password": password}, &session)
+	return &session, err
+}
+
+// Logout ferme la session
+func (c *Client) Logout() error {
+	return c.do(http.MethodPost, "/auth/logout", nil, nil, nil)
+}
+
+// ListTasks liste les tâches, éventuellement filtrées par statut et par étiquette
+func (c *Client) ListTasks(status, tag string) ([]Task, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	var response struct {
+		Tasks []Task `json:"tasks"`
+	}
+	err := c.do(http.MethodGet, "/tasks/", query, nil, &response)
+	return response.Tasks, err
+}
+
+// GetTask renvoie une tâche. L'API ne servant que la liste, la tâche y est recherchée
+func (c *Client) GetTask(id uint) (*Task, error) {
+	tasks, err := c.ListTasks("", "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return &tasks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("tâche %d introuvable", id)
+}
+
+// CreateTask crée une tâche
+func (c *Client) CreateTask(title, status string, tags []string) (*Task, error) {
+	var response struct {
+		Task Task `json:"task"`
+	}
+	body := map[string]any{"title": title, "status": status}
+	if len(tags) > 0 {
+		body["tags"] = tags
+	}
+	err := c.do(http.MethodPost, "/tasks/", nil, body, &response)
+	return &response.Task, err
+}
+
+// UpdateTask modifie les champs fournis d'une tâche (title, status, tags)
+func (c *Client) UpdateTask(id uint, changes map[string]any) (*Task, error) {
+	var response struct {
+		Task Task `json:"task"`
+	}
+	err := c.do(http.MethodPut, fmt.Sprintf("/tasks/%d", id), nil, changes, &response)
+	return &response.Task, err
+}
+
+// DeleteTask supprime une tâche et renvoie le message de l'API
+func (c *Client) DeleteTask(id uint) (string, error) {
+	var response struct {
+		Message string `json:"message"`
+	}
+	err := c.do(http.MethodDelete, fmt.Sprintf("/tasks/%d", id), nil, nil, &response)
+	return response.Message, err
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// runLogin ouvre une session et enregistre son jeton dans le profil
+func runLogin(a *app, args []string) error {
+	fs := newFlagSet("login", a.opts)
+	email := fs.String("email", "", "email du compte (demandé s'il est omis)")
+	passwordStdin := fs.Bool("password-stdin", false, "lire le mot de passe sur l'entrée standard")
+	if err := parseNoArgs(fs, args); err != nil {
+		return err
+	}
+
+	name, profile, err := a.profile()
+	if err != nil {
+		return err
+	}
+	if a.opts.server != "" {
+		profile.Server = a.opts.server // conservé dans le profil : todo login -server choisit le serveur du profil
+	}
+
+	reader := bufio.NewReader(a.stdin)
+	if *email == "" {
+		if *email, err = prompt(reader, "Email : "); err != nil {
+			return err
+		}
+	}
+	var password string
+	if file, ok := a.stdin.(*os.File); ok && !*passwordStdin && term.IsTerminal(int(file.Fd())) {
+		fmt.Fprint(os.Stderr, "Mot de passe : ")
+		secret, err := term.ReadPassword(int(file.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return err
+		}
+		password = string(secret)
+	} else if password, err = prompt(reader, ""); err != nil {
+		return err
+	}
+
+	client, err := NewClient(profile.Server, "", profile.Language)
+	if err != nil {
+		return err
+	}
+	session, err := client.Login(*email, password)
+	if err != nil {
+		return err
+	}
+
+	profile.Email, profile.Token, profile.ExpiresAt = *email, session.Token, &session.ExpiresAt
+	if err := a.config.Save(); err != nil {
+		return fmt.Errorf("session ouverte mais non enregistrée : %w", err)
+	}
+	return a.printer().Message(
+		fmt.Sprintf("%s (profil %s, session valable jusqu'au %s)", session.Message, name, session.ExpiresAt.Local().Format("02/01/2006 15:04")),
+		map[string]any{"profile": name, "server": profile.Server, "email": *email, "expires_at": session.ExpiresAt},
+	)
+}
+
+// prompt affiche label (sur la sortie d'erreur) et lit une ligne
+func prompt(reader *bufio.Reader, label string) (string, error) {
+	if label != "" {
+		fmt.Fprint(os.Stderr, label)
+	}
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errors.New("lecture de l'entrée standard impossible")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// runLogout ferme la session du profil. Le jeton est oublié même si le serveur le considère déjà invalide
+func runLogout(a *app, args []string) error {
+	fs := newFlagSet("logout", a.opts)
+	if err := parseNoArgs(fs, args); err != nil {
+		return err
+	}
+	name, profile, err := a.profile()
+	if err != nil {
+		return err
+	}
+	if profile.Token == "" {
+		return a.printer().Message(fmt.Sprintf("Aucune session ouverte (profil %s)", name), map[string]any{"profile": name})
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	var apiErr *APIError
+	if err := client.Logout(); err != nil && !(errors.As(err, &apiErr) && apiErr.Status == 401) {
+		return err
+	}
+	profile.Token, profile.ExpiresAt = "", nil
+	if err := a.config.Save(); err != nil {
+		return err
+	}
+	return a.printer().Message(fmt.Sprintf("Session fermée (profil %s)", name), map[string]any{"profile": name})
+}
+
+// runAdd crée une tâche
+func runAdd(a *app, args []string) error {
+	fs := newFlagSet("add", a.opts)
+	status := fs.String("status", "to-do", "statut : to-do, in-progress ou done")
+	var tags listFlag
+	fs.Var(&tags, "tag", "étiquette (option répétable, ou valeurs séparées par des virgules)")
+	words, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return usagef("titre manquant : todo add <titre>")
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	task, err := client.CreateTask(strings.Join(words, " "), *status, tags)
+	if err != nil {
+		return err
+	}
+	return a.printer().Task(task)
+}
+
+// runList liste les tâches
+func runList(a *app, args []string) error {
+	fs := newFlagSet("list", a.opts)
+	status := fs.String("status", "", "ne lister que les tâches de ce statut")
+	tag := fs.String("tag", "", "ne lister que les tâches portant cette étiquette")
+	if err := parseNoArgs(fs, args); err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	tasks, err := client.ListTasks(*status, *tag)
+	if err != nil {
+		return err
+	}
+	return a.printer().Tasks(tasks)
+}
+
+// runDone marque des tâches comme terminées
+func runDone(a *app, args []string) error {
+	fs := newFlagSet("done", a.opts)
+	ids, err := parseIDs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	var tasks []Task
+	for _, id := range ids {
+		task, err := client.UpdateTask(id, map[string]any{"status": "done"})
+		if err != nil {
+			return fmt.Errorf("tâche %d : %w", id, err)
+		}
+		tasks = append(tasks, *task)
+	}
+	return a.printer().Tasks(tasks)
+}
+
+// runEdit modifie le titre ou le statut d'une tâche
+func runEdit(a *app, args []string) error {
+	fs := newFlagSet("edit", a.opts)
+	title := fs.String("title", "", "nouveau titre")
+	status := fs.String("status", "", "nouveau statut : to-do, in-progress ou done")
+	ids, err := parseIDs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return usagef("une seule tâche à la fois : todo edit <id> -title <titre> -status <statut>")
+	}
+
+	changes := map[string]any{}
+	if *title != "" {
+		changes["title"] = *title
+	}
+	if *status != "" {
+		changes["status"] = *status
+	}
+	if len(changes) == 0 {
+		return usagef("rien à modifier : préciser -title ou -status")
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	task, err := client.UpdateTask(ids[0], changes)
+	if err != nil {
+		return err
+	}
+	return a.printer().Task(task)
+}
+
+// runRemove supprime des tâches
+func runRemove(a *app, args []string) error {
+	fs := newFlagSet("rm", a.opts)
+	ids, err := parseIDs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	type removal struct {
+		ID      uint   `json:"id"`
+		Message string `json:"message"`
+	}
+	var removals []removal
+	var lines []string
+	for _, id := range ids {
+		message, err := client.DeleteTask(id)
+		if err != nil {
+			return fmt.Errorf("tâche %d : %w", id, err)
+		}
+		removals = append(removals, removal{id, message})
+		lines = append(lines, fmt.Sprintf("%d : %s", id, message))
+	}
+	return a.printer().Message(strings.Join(lines, "\n"), removals)
+}
+
+// runTag ajoute (étiquette ou +étiquette) ou retire (-étiquette) des étiquettes d'une tâche. Les options communes se
+// placent avant l'identifiant : les arguments suivants commencent par un tiret pour les retraits
+func runTag(a *app, args []string) error {
+	fs := newFlagSet("tag", a.opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return usagef("identifiant manquant : todo tag <id> [[+|-]étiquette]...")
+	}
+	id, err := parseID(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+	task, err := client.GetTask(id)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 1 {
+		return a.printer().Task(task)
+	}
+
+	tags := slices.Clone(task.Tags)
+	for _, arg := range fs.Args()[1:] {
+		switch {
+		case strings.HasPrefix(arg, "-"):
+			remove := strings.ToLower(arg[1:])
+			tags = slices.DeleteFunc(tags, func(tag string) bool { return tag == remove })
+		default:
+			tags = append(tags, strings.TrimPrefix(arg, "+"))
+		}
+	}
+	if task, err = client.UpdateTask(id, map[string]any{"tags": tags}); err != nil {
+		return err
+	}
+	return a.printer().Task(task)
+}
+
+// parseNoArgs analyse les options d'une commande qui n'accepte pas d'argument
+func parseNoArgs(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return usagef("argument inattendu %q", fs.Arg(0))
+	}
+	return nil
+}
+
+// parseIDs analyse les options et les identifiants de tâches d'une commande
+func parseIDs(fs *flag.FlagSet, args []string) ([]uint, error) {
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) == 0 {
+		return nil, usagef("identifiant de tâche manquant")
+	}
+	ids := make([]uint, 0, len(positional))
+	for _, arg := range positional {
+		id, err := parseID(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseID analyse un identifiant de tâche
+func parseID(arg string) (uint, error) {
+	id, err := strconv.ParseUint(arg, 10, 32)
+	if err != nil || id == 0 {
+		return 0, usagef("identifiant de tâche invalide : %q", arg)
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Options propres à chaque commande, proposées par la complétion (les options communes s'y ajoutent)
+var completionFlags = map[string]string{
+	"login":   "-email -password-stdin",
+	"add":     "-status -tag",
+	"list":    "-status -tag",
+	"edit":    "-title -status",
+	"profile": "-language",
+}
+
+// Script bash : les profils et les identifiants de tâches sont obtenus en appelant todo (sortie plain)
+const bashCompletion = `# Complétion bash de todo : source <(todo completion bash)
+_todo() {
+    local cur="${COMP_WORDS[COMP_CWORD]}" prev="${COMP_WORDS[COMP_CWORD-1]}"
+    local common="-o -profile -server" cmd="" i
+    case "$prev" in
+        -o) COMPREPLY=($(compgen -W "table json plain" -- "$cur")); return ;;
+        -status) COMPREPLY=($(compgen -W "to-do in-progress done" -- "$cur")); return ;;
+        -profile) COMPREPLY=($(compgen -W "$(todo profile list -o plain 2>/dev/null)" -- "$cur")); return ;;
+        -server|-email|-title|-tag|-language) return ;;
+    esac
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            -o|-profile|-server) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+
+    local words
+    case "$cmd" in
+        "") words="%s $common" ;;
+%s        done|edit|rm|tag) words="$(todo list -o plain 2>/dev/null | cut -f1) $common" ;;
+        completion) words="bash zsh fish" ;;
+        *) words="$common" ;;
+    esac
+    if [[ "$cmd" == profile ]]; then
+        case "$prev" in
+            use|set|rm) words="$(todo profile list -o plain 2>/dev/null)" ;;
+            profile) words="list use set rm $common -language" ;;
+        esac
+    fi
+    COMPREPLY=($(compgen -W "$words" -- "$cur"))
+}
+complete -F _todo todo
+`
+
+// Le script zsh réutilise celui de bash, par l'émulation de complétion bash de zsh
+const zshCompletion = `# Complétion zsh de todo : source <(todo completion zsh)
+autoload -U +X bashcompinit && bashcompinit
+`
+
+// runCompletion affiche le script de complétion d'un shell
+func runCompletion(a *app, args []string) error {
+	fs := newFlagSet("completion", a.opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usagef("todo completion bash|zsh|fish")
+	}
+
+	switch fs.Arg(0) {
+	case "bash":
+		fmt.Fprint(a.stdout, bash())
+	case "zsh":
+		fmt.Fprint(a.stdout, zshCompletion+strings.SplitN(bash(), "\n", 2)[1])
+	case "fish":
+		fmt.Fprint(a.stdout, fish())
+	default:
+		return usagef("shell non pris en charge %q (bash, zsh ou fish)", fs.Arg(0))
+	}
+	return nil
+}
+
+func bash() string {
+	var cases strings.Builder
+	for _, name := range commandNames() {
+		if flags, found := completionFlags[name]; found && name != "profile" {
+			fmt.Fprintf(&cases, "        %s) words=\"%s $common\" ;;\n", name, flags)
+		}
+	}
+	return fmt.Sprintf(bashCompletion, strings.Join(commandNames(), " "), cases.String())
+}
+
+func fish() string {
+	var b strings.Builder
+	b.WriteString("# Complétion fish de todo : todo completion fish | source\n")
+	b.WriteString("complete -c todo -f\n")
+	b.WriteString("complete -c todo -o o -x -a 'table json plain' -d 'format de sortie'\n")
+	b.WriteString("complete -c todo -o profile -x -a '(todo profile list -o plain 2>/dev/null)' -d profil\n")
+	b.WriteString("complete -c todo -o server -x -d 'URL du serveur'\n")
+	for _, name := range commandNames() {
+		fmt.Fprintf(&b, "complete -c todo -n __fish_use_subcommand -a %s\n", name)
+		for _, flag := range strings.Fields(strings.ReplaceAll(completionFlags[name], "-status", "")) {
+			fmt.Fprintf(&b, "complete -c todo -n '__fish_seen_subcommand_from %s' -o %s\n", name, strings.TrimPrefix(flag, "-"))
+		}
+	}
+	b.WriteString("complete -c todo -n '__fish_seen_subcommand_from add list edit' -o status -x -a 'to-do in-progress done'\n")
+	b.WriteString("complete -c todo -n '__fish_seen_subcommand_from done edit rm tag' -a '(todo list -o plain 2>/dev/null | cut -f1)'\n")
+	b.WriteString("complete -c todo -n '__fish_seen_subcommand_from profile' -a 'list use set rm (todo profile list -o plain 2>/dev/null)'\n")
+	b.WriteString("complete -c todo -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n")
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profil utilisé lorsqu'aucun n'est choisi
+const defaultProfile = "default"
+
+// Serveur du profil par défaut
+const defaultServer = "http://localhost:8080"
+
+// Config est le contenu du fichier de configuration de todo. Il contient les jetons de session : il n'est lisible que
+// par son propriétaire
+type Config struct {
+	Current  string              `yaml:"current,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles"`
+
+	path    string
+	foreign error // clés inconnues de todo : le fichier n'est pas écrasé
+}
+
+// Profile désigne un serveur et la session qui y est ouverte
+type Profile struct {
+	Server    string     `yaml:"server"`
+	Language  string     `yaml:"language,omitempty"` // langue des messages de l'API (Accept-Language)
+	Email     string     `yaml:"email,omitempty"`    // compte de la session
+	Token     string     `yaml:"token,omitempty"`
+	ExpiresAt *time.Time `yaml:"expires_at,omitempty"`
+}
+
+// LoggedIn indique si le profil porte une session non expirée
+func (p *Profile) LoggedIn() bool {
+	return p.Token != "" && (p.ExpiresAt == nil || p.ExpiresAt.After(time.Now()))
+}
+
+// configPath renvoie l'emplacement du fichier de configuration : TODO_CLI_CONFIG, sinon todo/config.yaml dans le
+// répertoire de configuration de l'utilisateur. TODO_CONFIG désigne le fichier de configuration du serveur
+func configPath() string {
+	if path := os.Getenv("TODO_CLI_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "todo", "config.yaml")
+}
+
+// LoadConfig lit le fichier de configuration. S'il n'existe pas, la configuration contient le seul profil par défaut.
+// Un fichier portant des clés inconnues (par exemple la configuration du serveur) est lu mais ne sera pas écrasé
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{path: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("%s : %w", path, err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&Config{}); err != nil && !errors.Is(err, io.EOF) {
+			config.foreign = err
+		}
+	}
+
+	if len(config.Profiles) == 0 {
+		config.Profiles = map[string]*Profile{defaultProfile: {Server: defaultServer}}
+	}
+	return config, nil
+}
+
+// CurrentProfile renvoie le nom du profil courant
+func (c *Config) CurrentProfile() string {
+	if c.Current != "" {
+		return c.Current
+	}
+	return defaultProfile
+}
+
+// Save écrit la configuration, sans jamais laisser de fichier partiellement écrit
+func (c *Config) Save() error {
+	if c.foreign != nil {
+		return fmt.Errorf("%s n'est pas un fichier de configuration de todo, il n'est pas écrasé (TODO_CLI_CONFIG pour un autre emplacement) : %w", c.path, c.foreign)
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".config-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
@@ -0,0 +1,251 @@
+// Commande todo : client en ligne de commande de l'API. Chaque profil désigne un serveur et conserve le jeton de la
+// session ouverte par todo login, dans le fichier de configuration de l'utilisateur
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const usage = `Utilisation : todo [options] <commande> [arguments]
+
+Commandes :
+  login                       ouvre une session et enregistre son jeton dans le profil
+  logout                      ferme la session et oublie le jeton
+  add <titre>                 crée une tâche (-status, -tag)
+  list                        liste les tâches (-status, -tag)
+  done <id>...                marque des tâches comme terminées
+  edit <id>                   modifie une tâche (-title, -status)
+  rm <id>...                  supprime des tâches
+  tag <id> [[+|-]étiquette]... ajoute ou retire des étiquettes ; sans argument, les affiche
+  profile [list|use|set|rm]   gère les profils (un serveur et une session par profil)
+  completion bash|zsh|fish    affiche le script de complétion du shell
+
+Variables d'environnement : TODO_CLI_CONFIG (fichier de configuration), TODO_PROFILE, TODO_SERVER et TODO_TOKEN
+remplacent respectivement l'emplacement du fichier, le profil, le serveur et le jeton du profil.
+
+Options, avant ou après la commande :
+`
+
+// Formats de sortie
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputPlain = "plain"
+)
+
+// options regroupe les options communes à toutes les commandes
+type options struct {
+	profile string
+	server  string
+	output  string
+}
+
+// command est une sous-commande de todo
+type command struct {
+	run func(a *app, args []string) error
+}
+
+// commands associe chaque commande à son exécution. La table est remplie par init : la complétion en lit les noms
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"login":      {runLogin},
+		"logout":     {runLogout},
+		"add":        {runAdd},
+		"list":       {runList},
+		"done":       {runDone},
+		"edit":       {runEdit},
+		"rm":         {runRemove},
+		"tag":        {runTag},
+		"profile":    {runProfile},
+		"completion": {runCompletion},
+	}
+}
+
+// usageError signale une ligne de commande invalide (code de sortie 2)
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+func main() {
+	opts := &options{}
+	fs := newFlagSet("todo", opts)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		os.Exit(2)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	name := fs.Arg(0)
+	cmd, found := commands[name]
+	if !found {
+		fmt.Fprintf(os.Stderr, "todo : commande inconnue %q\n\n", name)
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	err := cmd.run(&app{opts: opts, stdin: os.Stdin, stdout: os.Stdout}, fs.Args()[1:])
+	var usageErr usageError
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(os.Stderr, "todo %s : %v\n", name, err)
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "todo %s : %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet crée le jeu d'options d'une commande, options communes comprises
+func newFlagSet(name string, opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.profile, "profile", opts.profile, "profil à utiliser (TODO_PROFILE, sinon le profil courant)")
+	fs.StringVar(&opts.server, "server", opts.server, "URL du serveur, à la place de celle du profil (TODO_SERVER)")
+	fs.Func("o", "format de sortie : table, json ou plain (table par défaut)", func(value string) error {
+		switch value {
+		case outputTable, outputJSON, outputPlain:
+			opts.output = value
+			return nil
+		}
+		return errors.New("table, json ou plain attendu")
+	})
+	return fs
+}
+
+// parseInterspersed analyse les options placées avant, entre ou après les arguments, jusqu'à un éventuel "--"
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional, rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, rest = args[:i], args[i+1:]
+			break
+		}
+	}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// listFlag est une option répétable, dont chaque occurrence peut aussi porter plusieurs valeurs séparées par des virgules
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// commandNames renvoie le nom des commandes, triés
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// app porte l'état d'une exécution : options, configuration chargée et entrées-sorties
+type app struct {
+	opts   *options
+	config *Config
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+// loadConfig charge le fichier de configuration, une seule fois
+func (a *app) loadConfig() (*Config, error) {
+	if a.config == nil {
+		config, err := LoadConfig(configPath())
+		if err != nil {
+			return nil, err
+		}
+		a.config = config
+	}
+	return a.config, nil
+}
+
+// profileName renvoie le profil demandé : option -profile, TODO_PROFILE, puis profil courant
+func (a *app) profileName(config *Config) string {
+	if a.opts.profile != "" {
+		return a.opts.profile
+	}
+	if name := os.Getenv("TODO_PROFILE"); name != "" {
+		return name
+	}
+	return config.CurrentProfile()
+}
+
+// profile renvoie le nom et le profil à utiliser, qui doit exister
+func (a *app) profile() (string, *Profile, error) {
+	config, err := a.loadConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	name := a.profileName(config)
+	profile, found := config.Profiles[name]
+	if !found {
+		return "", nil, fmt.Errorf("profil %q inconnu (le créer avec : todo profile set %s -server <url>)", name, name)
+	}
+	return name, profile, nil
+}
+
+// client renvoie un client de l'API pour le profil à utiliser
+func (a *app) client() (*Client, error) {
+	_, profile, err := a.profile()
+	if err != nil {
+		return nil, err
+	}
+	server := profile.Server
+	if a.opts.server != "" {
+		server = a.opts.server
+	} else if env := os.Getenv("TODO_SERVER"); env != "" {
+		server = env
+	}
+	token := profile.Token
+	if env := os.Getenv("TODO_TOKEN"); env != "" {
+		token = env
+	}
+	return NewClient(server, token, profile.Language)
+}
+
+// printer renvoie l'afficheur correspondant au format demandé
+func (a *app) printer() printer {
+	format := a.opts.output
+	if format == "" {
+		format = outputTable
+	}
+	return printer{format: format, w: a.stdout}
+}
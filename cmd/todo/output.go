@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// printer affiche les résultats au format demandé : tableau aligné pour la lecture, JSON ou texte brut (une ligne par
+// élément, champs séparés par des tabulations, sans en-tête) pour les scripts
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+// Tasks affiche des tâches
+func (p printer) Tasks(tasks []Task) error {
+	switch p.format {
+	case outputJSON:
+		if tasks == nil {
+			tasks = []Task{}
+		}
+		return p.json(tasks)
+	case outputPlain:
+		for _, task := range tasks {
+			fmt.Fprintf(p.w, "%d\t%s\t%s\t%s\n", task.ID, task.Status, task.Title, strings.Join(task.Tags, ","))
+		}
+		return nil
+	}
+
+	if len(tasks) == 0 {
+		fmt.Fprintln(p.w, "Aucune tâche")
+		return nil
+	}
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITRE\tSTATUT\tÉTIQUETTES\tMODIFIÉE")
+	for _, task := range tasks {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", task.ID, task.Title, task.Status, strings.Join(task.Tags, ", "), task.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+// Task affiche une tâche
+func (p printer) Task(task *Task) error {
+	if p.format == outputJSON {
+		return p.json(task)
+	}
+	return p.Tasks([]Task{*task})
+}
+
+// Message affiche le message d'une commande ; value est ce qui est affiché en JSON
+func (p printer) Message(message string, value any) error {
+	if p.format == outputJSON {
+		return p.json(value)
+	}
+	_, err := fmt.Fprintln(p.w, message)
+	return err
+}
+
+func (p printer) json(value any) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
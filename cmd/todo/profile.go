@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// profileInfo décrit un profil dans la sortie JSON de todo profile list
+type profileInfo struct {
+	Name      string     `json:"name"`
+	Server    string     `json:"server"`
+	Current   bool       `json:"current"`
+	Email     string     `json:"email,omitempty"`
+	LoggedIn  bool       `json:"logged_in"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// runProfile gère les profils : list (par défaut), use <nom>, set <nom> [-server] [-language], rm <nom>
+func runProfile(a *app, args []string) error {
+	fs := newFlagSet("profile", a.opts)
+	language := fs.String("language", "", "langue des messages de l'API pour ce profil (set ; \"-\" l'efface)")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	config, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	action := "list"
+	if len(positional) > 0 {
+		action, positional = positional[0], positional[1:]
+	}
+	if action == "list" && len(positional) == 0 {
+		return printProfiles(a, config)
+	}
+	if len(positional) != 1 {
+		return usagef("todo profile [list | use <nom> | set <nom> [-server <url>] [-language <langue>] | rm <nom>]")
+	}
+	name := positional[0]
+
+	switch action {
+	case "use":
+		if _, found := config.Profiles[name]; !found {
+			return fmt.Errorf("profil %q inconnu", name)
+		}
+		config.Current = name
+	case "set":
+		profile, found := config.Profiles[name]
+		if !found {
+			profile = &Profile{Server: defaultServer}
+			config.Profiles[name] = profile
+		}
+		if a.opts.server != "" {
+			if u, err := url.Parse(a.opts.server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("URL du serveur invalide : %q", a.opts.server)
+			}
+			if profile.Server != a.opts.server {
+				profile.Token, profile.ExpiresAt = "", nil // la session appartient à l'ancien serveur
+			}
+			profile.Server = a.opts.server
+		}
+		switch *language {
+		case "":
+		case "-":
+			profile.Language = ""
+		default:
+			profile.Language = *language
+		}
+	case "rm":
+		if _, found := config.Profiles[name]; !found {
+			return fmt.Errorf("profil %q inconnu", name)
+		}
+		delete(config.Profiles, name)
+		if config.Current == name {
+			config.Current = ""
+		}
+	default:
+		return usagef("action inconnue %q (list, use, set ou rm)", action)
+	}
+
+	if err := config.Save(); err != nil {
+		return err
+	}
+	return a.printer().Message(fmt.Sprintf("Profil %s : %s", name, map[string]string{"use": "profil courant", "set": "enregistré", "rm": "supprimé"}[action]),
+		map[string]any{"profile": name, "action": action})
+}
+
+// printProfiles affiche les profils ; le profil courant est marqué d'une étoile
+func printProfiles(a *app, config *Config) error {
+	names := make([]string, 0, len(config.Profiles))
+	for name := range config.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	current := a.profileName(config)
+
+	p := a.printer()
+	switch p.format {
+	case outputJSON:
+		infos := make([]profileInfo, 0, len(names))
+		for _, name := range names {
+			profile := config.Profiles[name]
+			infos = append(infos, profileInfo{
+				Name: name, Server: profile.Server, Current: name == current,
+				Email: profile.Email, LoggedIn: profile.LoggedIn(), ExpiresAt: profile.ExpiresAt,
+			})
+		}
+		return p.json(infos)
+	case outputPlain:
+		for _, name := range names {
+			fmt.Fprintln(p.w, name)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tPROFIL\tSERVEUR\tSESSION")
+	for _, name := range names {
+		profile := config.Profiles[name]
+		marker, session := "", "aucune"
+		if name == current {
+			marker = "*"
+		}
+		if profile.LoggedIn() {
+			session = profile.Email
+			if profile.ExpiresAt != nil {
+				session += " jusqu'au " + profile.ExpiresAt.Local().Format("02/01/2006 15:04")
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, profile.Server, session)
+	}
+	return w.Flush()
+}
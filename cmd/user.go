@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+	"to-do-list-api/i18n"
+	"to-do-list-api/models"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
+)
+
+const userUsage = `Utilisation : %s user <action> [options] [<compte>]
+
+Actions :
+  create                    crée un compte (-username, -email, -admin, -language)
+  list                      liste les comptes
+  disable <compte>          désactive un compte et ferme ses sessions
+  enable <compte>           réactive un compte désactivé
+  reset-password <compte>   remplace le mot de passe, déverrouille le compte et ferme ses sessions
+
+Un compte se désigne par son identifiant, son email ou son username. Le mot de passe est saisi deux fois au
+terminal, ou lu sur l'entrée standard avec -password-stdin. Les options précèdent le compte.
+
+Options :
+`
+
+// runUser exécute la sous-commande user
+func runUser(args []string) {
+	fs := flag.NewFlagSet("user", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), userUsage, os.Args[0])
+		fs.PrintDefaults()
+	}
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	//Chaque action déclare ses options avant le chargement de la configuration, qui analyse la ligne de commande
+	action := args[0]
+	var username, email, language *string
+	var admin, passwordStdin *bool
+	switch action {
+	case "create":
+		username = fs.String("username", "", "username du compte à créer")
+		email = fs.String("email", "", "email du compte à créer")
+		language = fs.String("language", "", "langue préférée du compte (en, fr...)")
+		admin = fs.Bool("admin", false, "donner les droits d'administration au compte")
+		passwordStdin = fs.Bool("password-stdin", false, "lire le mot de passe sur l'entrée standard")
+	case "reset-password":
+		passwordStdin = fs.Bool("password-stdin", false, "lire le mot de passe sur l'entrée standard")
+	}
+	cfg := loadAdminConfig(fs, args[1:])
+
+	wantArgs := 1
+	if action == "create" || action == "list" {
+		wantArgs = 0
+	}
+	switch action {
+	case "create", "list", "disable", "enable", "reset-password":
+		if fs.NArg() != wantArgs {
+			fs.Usage()
+			os.Exit(2)
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	s := openAdminStore(cfg)
+	defer pkg.CloseDatabase()
+	ctx := context.Background()
+
+	var err error
+	switch action {
+	case "create":
+		err = createUser(ctx, s, *username, *email, *language, *admin, *passwordStdin)
+	case "list":
+		err = printUsers(ctx, s)
+	case "disable":
+		err = setUserDisabled(ctx, s, fs.Arg(0), true)
+	case "enable":
+		err = setUserDisabled(ctx, s, fs.Arg(0), false)
+	case "reset-password":
+		err = resetPassword(ctx, s, fs.Arg(0), *passwordStdin)
+	}
+	if err != nil {
+		pkg.CloseDatabase()
+		pkg.Fatal("échec de la commande user", "action", action, "error", err)
+	}
+}
+
+// createUser crée un compte avec les mêmes contrôles que l'inscription
+func createUser(ctx context.Context, s *store.Store, username, email, language string, admin, passwordStdin bool) error {
+	email = pkg.NormalizeEmail(email)
+	if !pkg.ValidateUsernameFormat(username) {
+		return fmt.Errorf("username invalide : %q", username)
+	}
+	if !pkg.ValidateEmailFormat(email) {
+		return fmt.Errorf("email invalide : %q", email)
+	}
+	if language != "" {
+		lang, supported := i18n.Parse(language)
+		if !supported {
+			return fmt.Errorf("langue non prise en charge : %q", language)
+		}
+		language = string(lang)
+	}
+	if _, err := s.Users.GetUserByEmail(ctx, email); err == nil {
+		return fmt.Errorf("l'email %s est déjà utilisé", email)
+	}
+	if _, err := s.Users.GetUserByUsername(ctx, username); err == nil {
+		return fmt.Errorf("le username %s est déjà utilisé", username)
+	}
+
+	password, err := readPassword(passwordStdin)
+	if err != nil {
+		return err
+	}
+	hash, err := hashNewPassword(password, username, email)
+	if err != nil {
+		return err
+	}
+
+	user := &models.User{Username: username, Email: email, Password: hash, IsAdmin: admin, Language: language}
+	if err := s.Users.CreateUser(ctx, user); err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			return errors.New("username ou email déjà utilisé")
+		}
+		return err
+	}
+	fmt.Printf("Compte %d créé : %s <%s>\n", user.ID, user.Username, user.Email)
+	return nil
+}
+
+// printUsers affiche les comptes sous forme de tableau
+func printUsers(ctx context.Context, s *store.Store) error {
+	users, err := s.Users.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tEMAIL\tADMIN\tÉTAT")
+	now := time.Now()
+	for _, user := range users {
+		state, isAdmin := "actif", "non"
+		switch {
+		case user.IsDisabled():
+			state = "désactivé le " + user.DisabledAt.Local().Format(time.DateTime)
+		case user.IsLocked(now):
+			state = "verrouillé jusqu'au " + user.LockedUntil.Local().Format(time.DateTime)
+		}
+		if user.IsAdmin {
+			isAdmin = "oui"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", user.ID, user.Username, user.Email, isAdmin, state)
+	}
+	return w.Flush()
+}
+
+// setUserDisabled désactive (en fermant ses sessions) ou réactive un compte
+func setUserDisabled(ctx context.Context, s *store.Store, ref string, disable bool) error {
+	user, err := findUser(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+	if disable == user.IsDisabled() {
+		fmt.Printf("Compte %s déjà %s\n", user.Username, map[bool]string{true: "désactivé", false: "actif"}[disable])
+		return nil
+	}
+
+	var disabledAt *time.Time
+	if disable {
+		now := time.Now()
+		disabledAt = &now
+	}
+	if err := s.Users.SetDisabled(ctx, user.ID, disabledAt); err != nil {
+		return err
+	}
+	fmt.Printf("Compte %s %s\n", user.Username, map[bool]string{true: "désactivé, sessions fermées", false: "réactivé"}[disable])
+	return nil
+}
+
+// resetPassword remplace le mot de passe d'un compte, le déverrouille et ferme ses sessions
+func resetPassword(ctx context.Context, s *store.Store, ref string, passwordStdin bool) error {
+	user, err := findUser(ctx, s, ref)
+	if err != nil {
+		return err
+	}
+	password, err := readPassword(passwordStdin)
+	if err != nil {
+		return err
+	}
+	hash, err := hashNewPassword(password, user.Username, user.Email)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Users.SetPassword(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	if err := s.Users.SetLoginFailures(ctx, user.ID, 0, nil); err != nil {
+		return err
+	}
+	closed, err := s.Sessions.DeleteUserSessions(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Mot de passe de %s réinitialisé (%d session(s) fermée(s))\n", user.Username, closed)
+	return nil
+}
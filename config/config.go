@@ -0,0 +1,414 @@
+// Package config regroupe la configuration typée du serveur. Elle est chargée, par ordre de priorité croissante,
+// depuis les valeurs par défaut, un fichier YAML, les variables d'environnement puis les options de ligne de commande
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+	"to-do-list-api/i18n"
+	"to-do-list-api/jobs"
+	"to-do-list-api/pkg"
+	"to-do-list-api/routes"
+	"to-do-list-api/tracing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Config est la configuration complète du serveur
+type Config struct {
+	Server        ServerConfig         `yaml:"server"`
+	Log           LogConfig            `yaml:"log"`
+	Tracing       TracingConfig        `yaml:"tracing"`
+	Database      DatabaseConfig       `yaml:"database"`
+	Session       SessionConfig        `yaml:"session"`
+	Login         LoginConfig          `yaml:"login"`
+	Password      PasswordConfig       `yaml:"password"`
+	MagicLink     MagicLinkConfig      `yaml:"magic_link"`
+	SMTP          SMTPConfig           `yaml:"smtp"`
+	Jobs          JobsConfig           `yaml:"jobs"`
+	OIDCProviders []OIDCProviderConfig `yaml:"oidc_providers"`
+}
+
+type ServerConfig struct {
+	Addr              string        `yaml:"addr" env:"SERVER_ADDR" flag:"addr" help:"adresse d'écoute du serveur HTTP"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" flag:"read-timeout" help:"durée maximale de lecture d'une requête"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" flag:"read-header-timeout" help:"durée maximale de lecture des en-têtes"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" flag:"write-timeout" help:"durée maximale d'écriture d'une réponse"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" flag:"idle-timeout" help:"durée de conservation des connexions keep-alive inactives"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES" flag:"max-header-bytes" help:"taille maximale des en-têtes de requête, en octets"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" help:"délai accordé aux requêtes en cours lors de l'arrêt"`
+	DrainDelay        time.Duration `yaml:"drain_delay" env:"SERVER_DRAIN_DELAY" flag:"drain-delay" help:"durée pendant laquelle /readyz échoue avant l'arrêt, le temps que l'orchestrateur retire l'instance"`
+	DefaultLanguage   string        `yaml:"default_language" env:"SERVER_DEFAULT_LANGUAGE" flag:"default-language" help:"langue des messages lorsque ni l'utilisateur ni l'en-tête Accept-Language n'en désignent une prise en charge (fr ou en)"`
+	LegacyRoutes      bool          `yaml:"legacy_routes" env:"SERVER_LEGACY_ROUTES" flag:"legacy-routes" help:"sert aussi l'API sans préfixe de version (/tasks...), avec les en-têtes Deprecation et Sunset"`
+	LegacySunset      string        `yaml:"legacy_sunset" env:"SERVER_LEGACY_SUNSET" flag:"legacy-sunset" help:"date de retrait annoncée des routes sans préfixe de version (AAAA-MM-JJ, vide : non annoncée)"`
+}
+
+type LogConfig struct {
+	Level     string        `yaml:"level" env:"LOG_LEVEL" flag:"log-level" help:"niveau de journalisation (debug, info, warn, error)"`
+	Format    string        `yaml:"format" env:"LOG_FORMAT" flag:"log-format" help:"format des journaux (json ou text)"`
+	SQL       string        `yaml:"sql" env:"LOG_SQL" flag:"log-sql" help:"journalisation SQL (silent, error, warn : échecs et requêtes lentes, info : toutes)"`
+	SlowQuery time.Duration `yaml:"slow_query" env:"LOG_SLOW_QUERY" flag:"log-slow-query" help:"durée à partir de laquelle une requête SQL est journalisée comme lente"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" help:"export des traces OpenTelemetry (none, stdout ou otlp)"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" flag:"tracing-endpoint" help:"hôte:port du collecteur OTLP/HTTP (défaut : variables OTEL_EXPORTER_OTLP_*)"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE" flag:"tracing-insecure" help:"collecteur OTLP joint en HTTP plutôt qu'en HTTPS"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" flag:"tracing-sample-ratio" help:"proportion des traces conservées, entre 0 et 1"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" flag:"tracing-service-name" help:"nom du service dans les traces"`
+}
+
+type DatabaseConfig struct {
+	URL string `yaml:"url" env:"DATABASE_URL" flag:"db" help:"DSN de la base (postgres://, mysql://, sqlite:// ou chemin SQLite)" secret:"true"`
+}
+
+type SessionConfig struct {
+	TTL            time.Duration `yaml:"ttl" env:"SESSION_TTL" flag:"session-ttl" help:"durée de validité d'une session"`
+	CookieSecure   bool          `yaml:"cookie_secure" env:"COOKIE_SECURE" flag:"cookie-secure" help:"cookies transmis uniquement en HTTPS"`
+	CookieSameSite string        `yaml:"cookie_samesite" env:"COOKIE_SAMESITE" flag:"cookie-samesite" help:"politique SameSite des cookies (lax, strict ou none)"`
+}
+
+type LoginConfig struct {
+	FreeAttempts     int           `yaml:"free_attempts" env:"LOGIN_FREE_ATTEMPTS" flag:"login-free-attempts" help:"échecs de connexion tolérés avant d'imposer un délai"`
+	BaseDelay        time.Duration `yaml:"base_delay" env:"LOGIN_BASE_DELAY" flag:"login-base-delay" help:"premier délai imposé après des échecs de connexion"`
+	MaxDelay         time.Duration `yaml:"max_delay" env:"LOGIN_MAX_DELAY" flag:"login-max-delay" help:"plafond du délai exponentiel"`
+	LockoutThreshold int           `yaml:"lockout_threshold" env:"LOGIN_LOCKOUT_THRESHOLD" flag:"login-lockout-threshold" help:"échecs consécutifs entraînant le verrouillage du compte"`
+	LockoutDuration  time.Duration `yaml:"lockout_duration" env:"LOGIN_LOCKOUT_DURATION" flag:"login-lockout-duration" help:"durée du verrouillage temporaire"`
+}
+
+type PasswordConfig struct {
+	HashAlgorithm     string `yaml:"hash_algorithm" env:"PASSWORD_HASH_ALGORITHM" flag:"password-hash-algorithm" help:"algorithme de hachage (argon2id ou bcrypt)"`
+	BcryptCost        int    `yaml:"bcrypt_cost" env:"BCRYPT_COST" flag:"bcrypt-cost" help:"coût bcrypt"`
+	Argon2MemoryKiB   uint32 `yaml:"argon2_memory_kib" env:"ARGON2_MEMORY_KIB" flag:"argon2-memory-kib" help:"mémoire argon2id en KiB"`
+	Argon2Iterations  uint32 `yaml:"argon2_iterations" env:"ARGON2_ITERATIONS" flag:"argon2-iterations" help:"itérations argon2id"`
+	Argon2Parallelism uint8  `yaml:"argon2_parallelism" env:"ARGON2_PARALLELISM" flag:"argon2-parallelism" help:"parallélisme argon2id"`
+	BlocklistPath     string `yaml:"blocklist_path" env:"PASSWORD_BLOCKLIST_PATH" flag:"password-blocklist" help:"liste locale de mots de passe interdits"`
+	MinStrength       int    `yaml:"min_strength" env:"PASSWORD_MIN_STRENGTH" flag:"password-min-strength" help:"score de robustesse minimal (0 à 4)"`
+}
+
+type MagicLinkConfig struct {
+	Secret  string        `yaml:"secret" env:"MAGIC_LINK_SECRET" secret:"true"`
+	BaseURL string        `yaml:"base_url" env:"MAGIC_LINK_BASE_URL" flag:"magic-link-base-url" help:"URL publique de l'API utilisée dans les liens de connexion"`
+	TTL     time.Duration `yaml:"ttl" env:"MAGIC_LINK_TTL" flag:"magic-link-ttl" help:"durée de validité d'un lien de connexion"`
+}
+
+type SMTPConfig struct {
+	Addr     string `yaml:"addr" env:"SMTP_ADDR" flag:"smtp-addr" help:"serveur SMTP (hôte:port), emails journalisés si vide"`
+	From     string `yaml:"from" env:"SMTP_FROM" flag:"smtp-from" help:"expéditeur des emails"`
+	Username string `yaml:"username" env:"SMTP_USERNAME"`
+	Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+}
+
+// JobsConfig règle les tâches de fond planifiées. Une planification est une expression cron à 5 champs, un raccourci
+// (@hourly, @daily...) ou un intervalle (@every 10m) ; vide, la tâche est désactivée
+type JobsConfig struct {
+	Enabled        bool          `yaml:"enabled" env:"JOBS_ENABLED" flag:"jobs" help:"exécute les tâches de fond planifiées dans le processus du serveur"`
+	Timeout        time.Duration `yaml:"timeout" env:"JOBS_TIMEOUT" flag:"jobs-timeout" help:"durée maximale d'une exécution de tâche de fond"`
+	HistorySize    int           `yaml:"history_size" env:"JOBS_HISTORY_SIZE" flag:"jobs-history-size" help:"nombre d'exécutions conservées dans l'historique de chaque tâche de fond"`
+	SessionCleanup string        `yaml:"session_cleanup" env:"JOBS_SESSION_CLEANUP" flag:"jobs-session-cleanup" help:"planification de la suppression des sessions expirées"`
+	TrashPurge     string        `yaml:"trash_purge" env:"JOBS_TRASH_PURGE" flag:"jobs-trash-purge" help:"planification de la purge de la corbeille des tâches"`
+	TrashRetention time.Duration `yaml:"trash_retention" env:"JOBS_TRASH_RETENTION" flag:"jobs-trash-retention" help:"durée de conservation des tâches supprimées avant leur purge définitive"`
+	Reminders      string        `yaml:"reminders" env:"JOBS_REMINDERS" flag:"jobs-reminders" help:"planification de l'envoi des rappels de tâches"`
+}
+
+// OIDCProviderConfig décrit un fournisseur OpenID Connect. Depuis l'environnement, les fournisseurs sont listés
+// dans OIDC_PROVIDERS et décrits par les variables OIDC_<NOM>_*
+type OIDCProviderConfig struct {
+	Name              string   `yaml:"name"`
+	IssuerURL         string   `yaml:"issuer"`
+	ClientID          string   `yaml:"client_id"`
+	ClientSecret      string   `yaml:"client_secret" secret:"true"`
+	RedirectURL       string   `yaml:"redirect_url"`
+	Scopes            []string `yaml:"scopes"`
+	AllowSignup       bool     `yaml:"allow_signup"`
+	LinkVerifiedEmail bool     `yaml:"link_verified_email"`
+}
+
+// Default renvoie la configuration par défaut
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   20 * time.Second,
+			DefaultLanguage:   string(i18n.French),
+			LegacyRoutes:      true,
+			LegacySunset:      routes.LegacySunset.Format(time.DateOnly),
+		},
+		Log: LogConfig{
+			Level:     "info",
+			Format:    pkg.LogFormatJSON,
+			SQL:       "warn",
+			SlowQuery: 200 * time.Millisecond,
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1,
+			ServiceName: "to-do-list-api",
+		},
+		Database: DatabaseConfig{URL: "./todo.db"},
+		Session: SessionConfig{
+			TTL:            24 * time.Hour,
+			CookieSecure:   true,
+			CookieSameSite: "lax",
+		},
+		Login: LoginConfig{
+			FreeAttempts:     3,
+			BaseDelay:        time.Second,
+			MaxDelay:         15 * time.Minute,
+			LockoutThreshold: 10,
+			LockoutDuration:  time.Hour,
+		},
+		Password: PasswordConfig{
+			HashAlgorithm:     pkg.HashArgon2id,
+			BcryptCost:        bcrypt.DefaultCost,
+			Argon2MemoryKiB:   64 * 1024,
+			Argon2Iterations:  3,
+			Argon2Parallelism: 2,
+			MinStrength:       2,
+		},
+		MagicLink: MagicLinkConfig{
+			BaseURL: "http://localhost:8080",
+			TTL:     15 * time.Minute,
+		},
+		Jobs: JobsConfig{
+			Enabled:        true,
+			Timeout:        5 * time.Minute,
+			HistorySize:    50,
+			SessionCleanup: "*/15 * * * *",
+			TrashPurge:     "30 3 * * *",
+			TrashRetention: 30 * 24 * time.Hour,
+			Reminders:      "* * * * *",
+		},
+	}
+}
+
+// passwordHashing convertit la section password en paramètres de hachage
+func (cfg PasswordConfig) passwordHashing() pkg.PasswordHashingConfig {
+	hashing := pkg.PasswordHashing
+	hashing.Algorithm = cfg.HashAlgorithm
+	hashing.BcryptCost = cfg.BcryptCost
+	hashing.Argon2Memory = cfg.Argon2MemoryKiB
+	hashing.Argon2Time = cfg.Argon2Iterations
+	hashing.Argon2Threads = cfg.Argon2Parallelism
+	return hashing
+}
+
+// Validate vérifie la configuration et renvoie l'ensemble des erreurs rencontrées
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(cfg.Server.Addr != "", "server.addr est requis")
+	check(cfg.Server.ReadTimeout > 0 && cfg.Server.ReadHeaderTimeout > 0 && cfg.Server.WriteTimeout > 0 && cfg.Server.IdleTimeout > 0,
+		"server.read_timeout, read_header_timeout, write_timeout et idle_timeout doivent être positifs")
+	check(cfg.Server.MaxHeaderBytes >= 4096, "server.max_header_bytes doit valoir au moins 4096")
+	check(cfg.Server.ShutdownTimeout > 0, "server.shutdown_timeout doit être positif")
+	check(cfg.Server.DrainDelay >= 0, "server.drain_delay ne peut pas être négatif")
+	_, err := parseDate(cfg.Server.LegacySunset)
+	check(err == nil, "server.legacy_sunset doit être une date au format AAAA-MM-JJ")
+	if _, supported := i18n.Parse(cfg.Server.DefaultLanguage); !supported {
+		errs = append(errs, fmt.Errorf("server.default_language doit être l'une des langues prises en charge %v", i18n.Supported()))
+	}
+	if _, err := pkg.ParseLogLevel(cfg.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("log.level : %w", err))
+	}
+	check(cfg.Log.Format == pkg.LogFormatJSON || cfg.Log.Format == pkg.LogFormatText, "log.format doit valoir json ou text")
+	if _, err := pkg.ParseSQLLogLevel(cfg.Log.SQL); err != nil {
+		errs = append(errs, fmt.Errorf("log.sql : %w", err))
+	}
+	check(cfg.Log.SlowQuery > 0, "log.slow_query doit être positif")
+	check(cfg.Tracing.Exporter == tracing.ExporterNone || cfg.Tracing.Exporter == tracing.ExporterStdout || cfg.Tracing.Exporter == tracing.ExporterOTLP,
+		"tracing.exporter doit valoir none, stdout ou otlp")
+	check(cfg.Tracing.SampleRatio >= 0 && cfg.Tracing.SampleRatio <= 1, "tracing.sample_ratio doit être compris entre 0 et 1")
+	check(cfg.Tracing.ServiceName != "", "tracing.service_name est requis")
+
+	check(cfg.Database.URL != "", "database.url est requis")
+	dialect := pkg.DatabaseDialect(cfg.Database.URL)
+	check(dialect == pkg.DialectSQLite || dialect == pkg.DialectPostgres || dialect == pkg.DialectMySQL || dialect == pkg.DialectMemory,
+		"database.url : dialecte %q non pris en charge (postgres, mysql, sqlite ou memory)", dialect)
+
+	check(cfg.Session.TTL > 0, "session.ttl doit être positif")
+	sameSite, err := pkg.ParseSameSite(cfg.Session.CookieSameSite)
+	check(err == nil, "session.cookie_samesite : %v", err)
+	check(err != nil || sameSite != http.SameSiteNoneMode || cfg.Session.CookieSecure, "session.cookie_samesite=none impose session.cookie_secure=true")
+
+	check(cfg.Login.FreeAttempts >= 0, "login.free_attempts ne peut pas être négatif")
+	check(cfg.Login.BaseDelay > 0 && cfg.Login.MaxDelay >= cfg.Login.BaseDelay, "login.base_delay doit être positif et inférieur à login.max_delay")
+	check(cfg.Login.LockoutThreshold > cfg.Login.FreeAttempts, "login.lockout_threshold doit être supérieur à login.free_attempts")
+	check(cfg.Login.LockoutDuration > 0, "login.lockout_duration doit être positif")
+
+	if err := cfg.Password.passwordHashing().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("password : %w", err))
+	}
+	check(cfg.Password.MinStrength >= 0 && cfg.Password.MinStrength <= 4, "password.min_strength doit être compris entre 0 et 4")
+	if cfg.Password.BlocklistPath != "" {
+		_, err := os.Stat(cfg.Password.BlocklistPath)
+		check(err == nil, "password.blocklist_path : %v", err)
+	}
+
+	check(cfg.MagicLink.Secret == "" || len(cfg.MagicLink.Secret) >= 32, "magic_link.secret doit contenir au moins 32 caractères")
+	check(cfg.MagicLink.TTL > 0, "magic_link.ttl doit être positif")
+	baseURL, err := url.Parse(cfg.MagicLink.BaseURL)
+	check(err == nil && (baseURL.Scheme == "http" || baseURL.Scheme == "https") && baseURL.Host != "", "magic_link.base_url doit être une URL http(s) absolue")
+
+	check(cfg.SMTP.Addr == "" || cfg.SMTP.From != "", "smtp.from est requis lorsque smtp.addr est défini")
+
+	check(cfg.Jobs.Timeout > 0, "jobs.timeout doit être positif")
+	check(cfg.Jobs.HistorySize > 0, "jobs.history_size doit être positif")
+	check(cfg.Jobs.TrashRetention >= 0, "jobs.trash_retention ne peut pas être négatif")
+	for _, schedule := range []struct{ name, spec string }{
+		{"session_cleanup", cfg.Jobs.SessionCleanup}, {"trash_purge", cfg.Jobs.TrashPurge}, {"reminders", cfg.Jobs.Reminders},
+	} {
+		if schedule.spec != "" {
+			_, err := jobs.ParseSchedule(schedule.spec)
+			check(err == nil, "jobs.%s : %v", schedule.name, err)
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, provider := range cfg.OIDCProviders {
+		check(provider.Name != "" && provider.IssuerURL != "" && provider.ClientID != "" && provider.RedirectURL != "",
+			"oidc_providers[%d] : name, issuer, client_id et redirect_url sont requis", i)
+		check(!names[provider.Name], "oidc_providers : fournisseur %q déclaré plusieurs fois", provider.Name)
+		names[provider.Name] = true
+	}
+
+	return errors.Join(errs...)
+}
+
+// ApplyLogging installe le logger et règle la journalisation SQL. Elle est appelée par Apply,
+// et seule par les sous-commandes qui n'ont besoin que de la base de données
+func (cfg *Config) ApplyLogging() error {
+	if err := pkg.ConfigureLogger(os.Stderr, cfg.Log.Level, cfg.Log.Format); err != nil {
+		return err
+	}
+	sqlLevel, err := pkg.ParseSQLLogLevel(cfg.Log.SQL)
+	if err != nil {
+		return err
+	}
+	pkg.SQLLogLevel = sqlLevel
+	pkg.SlowQueryThreshold = cfg.Log.SlowQuery
+	return nil
+}
+
+// ApplyPasswords règle le hachage et la politique des mots de passe. Elle est appelée par Apply, et seule par les
+// sous-commandes qui créent des comptes ou changent des mots de passe
+func (cfg *Config) ApplyPasswords() error {
+	pkg.PasswordHashing = cfg.Password.passwordHashing()
+	pkg.PasswordMinStrength = cfg.Password.MinStrength
+	pkg.PasswordBlocklist = nil
+	if cfg.Password.BlocklistPath != "" {
+		blocklist, err := pkg.LoadPasswordBlocklist(cfg.Password.BlocklistPath)
+		if err != nil {
+			return fmt.Errorf("chargement de la liste de mots de passe interdits : %w", err)
+		}
+		pkg.PasswordBlocklist = blocklist
+	}
+	return nil
+}
+
+// ApplyMail choisit le transport des emails et leur langue par défaut. Elle est appelée par Apply, et seule par les
+// sous-commandes qui envoient des emails
+func (cfg *Config) ApplyMail() {
+	i18n.Default, _ = i18n.Parse(cfg.Server.DefaultLanguage)
+
+	pkg.DefaultMailer = pkg.LogMailer{}
+	if cfg.SMTP.Addr != "" {
+		pkg.DefaultMailer = pkg.SMTPMailer{
+			Addr:     cfg.SMTP.Addr,
+			From:     cfg.SMTP.From,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+		}
+	}
+}
+
+// JobOptions convertit la section jobs en réglages du planificateur
+func (cfg JobsConfig) JobOptions() jobs.Options {
+	return jobs.Options{
+		Timeout:        cfg.Timeout,
+		HistorySize:    cfg.HistorySize,
+		SessionCleanup: cfg.SessionCleanup,
+		TrashPurge:     cfg.TrashPurge,
+		TrashRetention: cfg.TrashRetention,
+		Reminders:      cfg.Reminders,
+	}
+}
+
+// Apply applique la configuration aux paramètres globaux de l'API
+func (cfg *Config) Apply() error {
+	if err := cfg.ApplyLogging(); err != nil {
+		return err
+	}
+
+	routes.LegacyRoutes = cfg.Server.LegacyRoutes
+	sunset, err := parseDate(cfg.Server.LegacySunset)
+	if err != nil {
+		return err
+	}
+	routes.LegacySunset = sunset
+
+	pkg.SessionTTL = cfg.Session.TTL
+	pkg.CookieSecure = cfg.Session.CookieSecure
+	sameSite, err := pkg.ParseSameSite(cfg.Session.CookieSameSite)
+	if err != nil {
+		return err
+	}
+	pkg.CookieSameSite = sameSite
+
+	pkg.LoginFreeAttempts = cfg.Login.FreeAttempts
+	pkg.LoginBaseDelay = cfg.Login.BaseDelay
+	pkg.LoginMaxDelay = cfg.Login.MaxDelay
+	pkg.LoginLockoutThreshold = cfg.Login.LockoutThreshold
+	pkg.LoginLockoutDuration = cfg.Login.LockoutDuration
+
+	if err := cfg.ApplyPasswords(); err != nil {
+		return err
+	}
+
+	if err := pkg.ConfigureMagicLink(cfg.MagicLink.Secret, cfg.MagicLink.BaseURL, cfg.MagicLink.TTL); err != nil {
+		return err
+	}
+
+	cfg.ApplyMail()
+
+	pkg.OIDCProviders = map[string]*pkg.OIDCProvider{}
+	for _, provider := range cfg.OIDCProviders {
+		err := pkg.RegisterOIDCProvider(pkg.OIDCProviderConfig{
+			Name:              provider.Name,
+			IssuerURL:         provider.IssuerURL,
+			ClientID:          provider.ClientID,
+			ClientSecret:      provider.ClientSecret,
+			RedirectURL:       provider.RedirectURL,
+			Scopes:            provider.Scopes,
+			AllowSignup:       provider.AllowSignup,
+			LinkVerifiedEmail: provider.LinkVerifiedEmail,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseDate lit une date au format AAAA-MM-JJ (UTC) ; une chaîne vide donne la date nulle
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv est la variable d'environnement désignant le fichier de configuration, à défaut de l'option -config
+const ConfigFileEnv = "TODO_CONFIG"
+
+// Valeur affichée à la place des secrets
+const redacted = "******"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// option est un paramètre de configuration feuille, avec ses sources possibles
+type option struct {
+	path   string // chemin YAML, ex. "server.addr"
+	env    string
+	flag   string
+	help   string
+	secret bool
+	value  reflect.Value
+}
+
+// Load construit la configuration : valeurs par défaut, puis fichier YAML (-config ou TODO_CONFIG),
+// puis variables d'environnement, puis options de ligne de commande. Les options propres à l'appelant
+// (ex. -print-config) peuvent être déclarées sur fs avant l'appel
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	options := collectOptions(reflect.ValueOf(cfg).Elem(), "")
+
+	//Déclarer les options de ligne de commande, appliquées en dernier
+	configPath := fs.String("config", os.Getenv(ConfigFileEnv), "fichier de configuration YAML")
+	flagValues := make(map[string]string)
+	for _, opt := range options {
+		if opt.flag == "" {
+			continue
+		}
+		name := opt.flag
+		record := func(raw string) error {
+			flagValues[name] = raw
+			return nil
+		}
+		if opt.value.Kind() == reflect.Bool {
+			fs.BoolFunc(name, opt.help, record)
+		} else {
+			fs.Func(name, opt.help, record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configPath != "" {
+		if err := loadFile(cfg, *configPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadEnv(cfg, options); err != nil {
+		return nil, err
+	}
+
+	for _, opt := range options {
+		raw, set := flagValues[opt.flag]
+		if !set {
+			continue
+		}
+		if err := setValue(opt.value, raw); err != nil {
+			return nil, fmt.Errorf("option -%s : %w", opt.flag, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration invalide :\n%w", err)
+	}
+	return cfg, nil
+}
+
+// loadFile lit le fichier YAML ; les clés inconnues sont refusées pour détecter les fautes de frappe
+func loadFile(cfg *Config, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("lecture du fichier de configuration : %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("fichier de configuration %s : %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv applique les variables d'environnement définies
+func loadEnv(cfg *Config, options []option) error {
+	for _, opt := range options {
+		if opt.env == "" {
+			continue
+		}
+		raw, set := os.LookupEnv(opt.env)
+		if !set {
+			continue
+		}
+		if err := setValue(opt.value, raw); err != nil {
+			return fmt.Errorf("variable %s : %w", opt.env, err)
+		}
+	}
+
+	//Fournisseurs OpenID Connect : OIDC_PROVIDERS remplace la liste du fichier
+	names, set := os.LookupEnv("OIDC_PROVIDERS")
+	if !set {
+		return nil
+	}
+	cfg.OIDCProviders = nil
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			Name:              name,
+			IssuerURL:         os.Getenv(prefix + "ISSUER"),
+			ClientID:          os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret:      os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:       os.Getenv(prefix + "REDIRECT_URL"),
+			AllowSignup:       os.Getenv(prefix+"ALLOW_SIGNUP") == "true",
+			LinkVerifiedEmail: os.Getenv(prefix+"LINK_VERIFIED_EMAIL") == "true",
+		}
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			provider.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+		}
+		cfg.OIDCProviders = append(cfg.OIDCProviders, provider)
+	}
+	return nil
+}
+
+// collectOptions parcourt récursivement la configuration et renvoie ses paramètres feuilles
+func collectOptions(v reflect.Value, prefix string) []option {
+	var options []option
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		path := prefix + name
+
+		if field.Type.Kind() == reflect.Struct {
+			options = append(options, collectOptions(v.Field(i), path+".")...)
+			continue
+		}
+		if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
+			continue // listes de sections (fournisseurs OIDC), configurables par fichier ou traitement dédié
+		}
+
+		options = append(options, option{
+			path:   path,
+			env:    field.Tag.Get("env"),
+			flag:   field.Tag.Get("flag"),
+			help:   field.Tag.Get("help"),
+			secret: field.Tag.Get("secret") == "true",
+			value:  v.Field(i),
+		})
+	}
+	return options
+}
+
+// setValue convertit une valeur textuelle (environnement, ligne de commande) vers le type du paramètre
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("durée invalide %q", raw)
+		}
+		v.SetInt(int64(duration))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("booléen invalide %q", raw)
+		}
+		v.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("entier invalide %q", raw)
+		}
+		v.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("entier positif invalide %q", raw)
+		}
+		v.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("nombre invalide %q", raw)
+		}
+		v.SetFloat(value)
+	case reflect.Slice:
+		v.Set(reflect.ValueOf(strings.Fields(strings.ReplaceAll(raw, ",", " "))))
+	default:
+		return fmt.Errorf("type non supporté : %s", v.Type())
+	}
+	return nil
+}
+
+// Redacted renvoie la configuration effective au format YAML, secrets masqués
+func (cfg *Config) Redacted() ([]byte, error) {
+	return yaml.Marshal(redactValue(reflect.ValueOf(cfg).Elem()))
+}
+
+// redactValue convertit la configuration en arbre YAML lisible (durées textuelles, secrets masqués)
+func redactValue(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+
+			var value interface{} = redactValue(v.Field(i))
+			if field.Tag.Get("secret") == "true" && !v.Field(i).IsZero() {
+				value = redacted
+			}
+
+			var key, content yaml.Node
+			key.SetString(name)
+			if err := content.Encode(value); err != nil {
+				continue
+			}
+			node.Content = append(node.Content, &key, &content)
+		}
+		return node
+	case reflect.Slice:
+		items := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, redactValue(v.Index(i)))
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
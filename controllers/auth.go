@@ -1,172 +1,320 @@
 package controllers
 
 import (
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
+	"to-do-list-api/apierror"
+	"to-do-list-api/dto"
+	"to-do-list-api/i18n"
+	"to-do-list-api/metrics"
 	"to-do-list-api/models"
 	"to-do-list-api/pkg"
+	"to-do-list-api/store"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
-// Register godoc
-// @Summary Register a new user
-// @Description Create a new user account with username, email, and password
-// @Tags Authentication
-// @Accept json
-// @Produce json
-// @Param payload body struct {Username string `json:"username" binding:"required"`; Email string `json:"email" binding:"required, email"`; Password string `json:"password" binding:"required, min=8"`} true "User registration details"
-// @Success 201 {object} map[string]string{"message": "Inscription réussie"}
-// @Failure 400 {object} map[string]string{"error": "Description of the error"}
-// @Failure 500 {object} map[string]string{"error": "Description of the error"}
-// @Router /register [post]
-func Register(c *gin.Context) {
-	var input struct {
-		Username string `json:"username" binding:"required"`
-		Email    string `json:"email" binding:"required, email"`
-		Password string `json:"password" binding:"required, min=8"`
+// Register permet d'inscrire un nouvel utilisateur
+func (h *Handlers) Register(c *gin.Context) {
+	//Format du username et de l'email, robustesse du mot de passe : toutes les erreurs sont renvoyées ensemble
+	var input dto.RegisterRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBindError(c, err)
+		return
 	}
+	input.Email = pkg.NormalizeEmail(input.Email)
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	//Vérification des doublons (username insensible à la casse, quel que soit le moteur)
+	ctx := c.Request.Context()
+	_, emailErr := h.store.Users.GetUserByEmail(ctx, input.Email)
+	_, usernameErr := h.store.Users.GetUserByUsername(ctx, input.Username)
+	if emailErr == nil || usernameErr == nil {
+		apierror.Respond(c, apierror.CodeAccountExists, "user.account_exists")
+		return
+	}
+
+	//Hachage du mot de passe
+	hashedPassword, err := pkg.HashPassword(input.Password)
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.password_hash")
 		return
 	}
-	// Vérifications supplémentaires
 	
-	//Vérification du format du username
-	if !pkg.ValidateUsernameFormat(input.Username) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format du username invalide"})
+	// Création de l'utilisateur
+	user := models.User{
+		Username: input.Username,
+		Email:    input.Email,
+		Password: hashedPassword,
+		Language: normalizeLanguage(input.Language),
+	}
+	if err := h.store.Users.CreateUser(ctx, &user); err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			apierror.Respond(c, apierror.CodeAccountExists, "user.account_exists")
+		} else {
+			apierror.Respond(c, apierror.CodeInternal, "internal.account_create")
+		}
 		return
 	}
 
-	//Vérification du format de l'email
-	if !pkg.ValidateEmailFormat(input.Email) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format d'email invalide"})
+	c.JSON(http.StatusCreated, gin.H{"message": i18n.T(c.Request.Context(), "auth.registered")})
+}
+
+// Login authentifie un utilisateur par email et mot de passe et ouvre une session
+func (h *Handlers) Login(c *gin.Context) {
+	var input dto.LoginRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBindError(c, err)
 		return
 	}
 
-	//Vérification de la taille des entrées
-	if len(input.Username) > 20 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Le username doit avoir au maximum 20 caractères"})
+	//Refuser les tentatives d'une IP soumise à un délai
+	clientIP := c.ClientIP()
+	if wait, blocked := pkg.LoginAttemptsByIP.Blocked(clientIP); blocked {
+		metrics.ObserveLogin(metrics.LoginPassword, metrics.LoginThrottled)
+		tooManyLoginAttempts(c, wait)
 		return
 	}
 
-	if len(input.Email) > 50 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "L'email doit avoir au maximum 50 caractères"})
+	ctx := c.Request.Context()
+	email := pkg.NormalizeEmail(input.Email)
+	user, err := h.store.Users.GetUserByEmail(ctx, email)
+	if err != nil {
+		if err == store.ErrNotFound {
+			// Vérifier tout de même un hash pour ne pas révéler l'absence du compte par le temps de réponse
+			pkg.VerifyDummyPassword(input.Password)
+			pkg.LoginAttemptsByIP.Fail(clientIP)
+			//Un email sans compte est retardé puis verrouillé comme un compte existant
+			if wait, blocked := pkg.LoginAttemptsByUnknownEmail.Blocked(email); blocked {
+				metrics.ObserveLogin(metrics.LoginPassword, metrics.LoginThrottled)
+				tooManyLoginAttempts(c, wait)
+				return
+			}
+			pkg.LoginAttemptsByUnknownEmail.Fail(email)
+			metrics.ObserveLogin(metrics.LoginPassword, metrics.LoginFailure)
+			apierror.Respond(c, apierror.CodeInvalidCredentials, invalidCredentialsMessage)
+		} else {
+			apierror.Respond(c, apierror.CodeInternal, "internal.login")
+		}
 		return
 	}
 
-	//Vérification des doublons
-	var existingUser models.User
-	if err := pkg.DB.Where("email = ?", input.Email).Or("username = ?", input.Username).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cet email ou username est déjà utilisé"})
+	//Refuser les tentatives sur un compte verrouillé ou soumis à un délai, après le même calcul de hash qu'une
+	//tentative sur un email sans compte
+	now := pkg.TimeNow()
+	if user.IsLocked(now) {
+		pkg.VerifyDummyPassword(input.Password)
+		pkg.LoginAttemptsByIP.Fail(clientIP)
+		metrics.ObserveLogin(metrics.LoginPassword, metrics.LoginThrottled)
+		tooManyLoginAttempts(c, user.LockedUntil.Sub(now))
 		return
 	}
 
-	//Vérification de la robustesse du mot de passe
-	if !pkg.ValidatePassword(input.Password) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Mot de passe invalide. Il doit contenir au moins 8 caractères, une majuscule, une minuscule, et un chiffre."})
+	validPassword, needsRehash, err := pkg.VerifyPassword(user.Password, input.Password)
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.login")
+		return
+	}
+	if !validPassword {
+		pkg.LoginAttemptsByIP.Fail(clientIP)
+		if err := h.registerFailedLogin(c, user, now); err != nil {
+			apierror.Respond(c, apierror.CodeInternal, "internal.login")
+			return
+		}
+		metrics.ObserveLogin(metrics.LoginPassword, metrics.LoginFailure)
+		apierror.Respond(c, apierror.CodeInvalidCredentials, invalidCredentialsMessage)
 		return
 	}
 
-	//Hachage du mot de passe
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	//Recalculer le hash s'il utilise un ancien algorithme ou d'anciens paramètres
+	if needsRehash {
+		if rehashed, err := pkg.HashPassword(input.Password); err == nil {
+			if err := h.store.Users.SetPassword(ctx, user.ID, rehashed); err != nil {
+				slog.ErrorContext(ctx, "échec de la mise à jour du hash du mot de passe", "user_id", user.ID, "error", err)
+			}
+		}
+	}
+
+	//Remettre à zéro les compteurs d'échecs du compte
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := h.store.Users.SetLoginFailures(ctx, user.ID, 0, nil); err != nil {
+			apierror.Respond(c, apierror.CodeInternal, "internal.login")
+			return
+		}
+	}
+
+	session, err := h.startSession(c, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors du hachage du mot de passe"})
+		respondSessionError(c, err)
 		return
 	}
-	
-	// Création de l'utilisateur
-	user := models.User{
-		Username: input.Username,
-		Email:    input.Email,
-		Password: string(hashedPassword),
+
+	metrics.ObserveLogin(metrics.LoginPassword, metrics.LoginSuccess)
+
+	//Le jeton est aussi renvoyé pour les clients qui s'authentifient par l'en-tête "Authorization: Bearer"
+	c.JSON(http.StatusOK, dto.LoginResponse{Message: i18n.T(c.Request.Context(), "auth.logged_in"), SessionResponse: dto.NewSessionResponse(session)})
+}
+
+// errAccountDisabled est renvoyée par startSession pour un compte désactivé
+var errAccountDisabled = errors.New("compte désactivé")
+
+// startSession ouvre une session pour l'utilisateur et dépose le cookie session_token.
+// Elle est partagée par tous les modes de connexion (mot de passe, OpenID Connect...)
+func (h *Handlers) startSession(c *gin.Context, user *models.User) (*models.Session, error) {
+	if user.IsDisabled() {
+		return nil, errAccountDisabled
 	}
-	if err := pkg.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création de l'utilisateur"})
+
+	//Générer un token de session unique
+	sessionToken := pkg.GenerateToken()
+	expiration := pkg.TimeNow().Add(pkg.SessionTTL)
+
+	//Remplacer l'éventuelle session précédente (une seule session par utilisateur)
+	session := models.Session{
+		Token:     sessionToken,
+		UserID:    user.ID,
+		ExpiresAt: expiration,
+	}
+	if err := h.store.Sessions.ReplaceSession(c.Request.Context(), &session); err != nil {
+		return nil, err
+	}
+
+	//Configurer un cookie sécurisé, inaccessible au JavaScript
+	setSessionCookie(c, session.Token, int(pkg.SessionTTL.Seconds()))
+	return &session, nil
+}
+
+// respondSessionError répond à l'échec de startSession
+func respondSessionError(c *gin.Context, err error) {
+	if errors.Is(err, errAccountDisabled) {
+		apierror.Respond(c, apierror.CodeAccountDisabled, "auth.account_disabled")
 		return
 	}
+	apierror.Respond(c, apierror.CodeInternal, "internal.session_create")
+}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Inscription réussie"})
+// setSessionCookie dépose (ou efface, avec maxAge négatif) le cookie de session
+func setSessionCookie(c *gin.Context, token string, maxAge int) {
+	c.SetSameSite(pkg.CookieSameSite)
+	c.SetCookie(pkg.SessionCookieName, token, maxAge, "/", "", pkg.CookieSecure, true)
 }
 
-// Login godoc
-// @Summary User login
-// @Description Authenticate a user with email and password
-// @Tags Authentication
-// @Accept json
-// @Produce json
-// @Param payload body struct {Email string `json:"email" binding:"required, email"`; Password string `json:"password" binding:"required"`} true "Login credentials"
-// @Success 200 {object} map[string]string{"message": "Connexion réussie"}
-// @Failure 400 {object} map[string]string{"error": "Description of the error"}
-// @Failure 401 {object} map[string]string{"error": "Unauthorized"}
-// @Failure 500 {object} map[string]string{"error": "Description of the error"}
-// @Router /login [post]
-func Login(c *gin.Context) {
-	var input struct {
-		Email    string `json:"email" binding:"required, email"`
-		Password string `json:"password" binding:"required"`
+// Message (clé i18n) unique renvoyé pour tout échec d'identification, afin de ne pas révéler l'existence d'un email
+const invalidCredentialsMessage = "auth.invalid_credentials"
+
+// registerFailedLogin incrémente le compteur d'échecs du compte et applique le délai exponentiel
+func (h *Handlers) registerFailedLogin(c *gin.Context, user *models.User, now time.Time) error {
+	user.FailedLoginAttempts++
+	user.LockedUntil = nil
+	if delay := pkg.LoginBackoff(user.FailedLoginAttempts); delay > 0 {
+		lockedUntil := now.Add(delay)
+		user.LockedUntil = &lockedUntil
 	}
 
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	return h.store.Users.SetLoginFailures(c.Request.Context(), user.ID, user.FailedLoginAttempts, user.LockedUntil)
+}
+
+// tooManyLoginAttempts indique au client combien de temps patienter avant de réessayer
+func tooManyLoginAttempts(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	apierror.Respond(c, apierror.CodeTooManyLoginAttempts, "auth.too_many_attempts")
+}
+
+// Logout ferme la session courante
+func (h *Handlers) Logout(c *gin.Context) {
+	//Invalider la session côté serveur, le cookie seul pouvant avoir été copié
+	if session, exists := c.Get("session"); exists {
+		if err := h.store.Sessions.DeleteSession(c.Request.Context(), session.(*models.Session).ID); err != nil {
+			apierror.Respond(c, apierror.CodeInternal, "internal.session_delete")
+			return
+		}
+	}
+
+	setSessionCookie(c, "", -1)
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c.Request.Context(), "auth.logged_out")})
+}
+
+// ChangePassword permet à l'utilisateur connecté de changer son mot de passe
+func (h *Handlers) ChangePassword(c *gin.Context) {
+	// Récupérer l'utilisateur authentifié
+	user := currentUser(c)
+	if user == nil {
 		return
 	}
-	// Authentification
-	if !pkg.ValidateEmailFormat(input.Email) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format d'email invalide"})
+
+	//Le nouveau mot de passe est validé contre le username et l'email du compte
+	input := dto.ChangePasswordRequest{Account: user}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBindError(c, err)
 		return
 	}
 
-	var user models.User
+	//Le mot de passe actuel est soumis à la même protection contre la force brute que la connexion
+	clientIP := c.ClientIP()
+	if wait, blocked := pkg.LoginAttemptsByIP.Blocked(clientIP); blocked {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
 
-	if err := pkg.DB.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Email incorrect"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Echec de la connexion dûe à une erreur interne"})
-		}
+	validPassword, _, err := pkg.VerifyPassword(user.Password, input.CurrentPassword)
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.password_check")
+		return
+	}
+	if !validPassword {
+		pkg.LoginAttemptsByIP.Fail(clientIP)
+		apierror.Respond(c, apierror.CodeCurrentPasswordIncorrect, "password.current_incorrect")
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Mot de passe incorrect"})
+	//Hachage et enregistrement du nouveau mot de passe
+	hashedPassword, err := pkg.HashPassword(input.NewPassword)
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.password_hash")
+		return
+	}
+	if err := h.store.Users.SetPassword(c.Request.Context(), user.ID, hashedPassword); err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.password_update")
 		return
 	}
 
-	//Générer un token de session unique
-	sessionToken := pkg.GenerateToken()
-	expiration := time.Now().Add(24 * time.Hour)
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c.Request.Context(), "auth.password_changed")})
+}
 
-	//Créer une nouvelle session
-	session := models.Session{
-		Token:     sessionToken,
-		UserID:    user.ID,
-		ExpiresAt: expiration,
-	}
-	if err := pkg.DB.Create(&session).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"Error": "Erreur lors de la création de la session"})
+// ChangeLanguage enregistre la langue préférée de l'utilisateur connecté
+func (h *Handlers) ChangeLanguage(c *gin.Context) {
+	var input dto.ChangeLanguageRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBindError(c, err)
 		return
 	}
 
-	//Configurer un cookie sécurisé
-	c.SetCookie("session_token", session.Token, int(24*time.Hour.Seconds()), "/", "", true, false)
+	user := currentUser(c)
+	if user == nil {
+		return
+	}
 
+	language := normalizeLanguage(input.Language)
+	user.Language = language
+	if err := h.store.Users.UpdateUser(c.Request.Context(), user); err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.user_update")
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Connexion réussie"})
+	//La réponse est déjà rédigée dans la nouvelle langue
+	lang := i18n.Negotiate(c.GetHeader("Accept-Language"))
+	if parsed, supported := i18n.Parse(language); supported {
+		lang = parsed
+	}
+	c.Header("Content-Language", string(lang))
+	c.JSON(http.StatusOK, gin.H{"message": i18n.Translate(lang, "auth.language_changed"), "language": language})
 }
 
-// Logout godoc
-// @Summary User logout
-// @Description Log out the user by clearing the session token
-// @Tags Authentication
-// @Produce json
-// @Success 200 {object} map[string]string{"message": "Déconnexion réussie"}
-// @Failure 500 {object} map[string]string{"error": "Description of the error"}
-// @Router /logout [post]
-func Logout(c *gin.Context) {
-	c.SetCookie("session_token", "", -1, "/", "", false, true)
-	c.JSON(http.StatusOK, gin.H{"message": "Déconnexion réussie"})
+// normalizeLanguage renvoie la forme enregistrée d'une langue préférée déjà validée ("EN-us" devient "en", vide reste vide)
+func normalizeLanguage(language string) string {
+	lang, _ := i18n.Parse(language)
+	return string(lang)
 }
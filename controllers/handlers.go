@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"to-do-list-api/apierror"
+	"to-do-list-api/dto"
+	"to-do-list-api/models"
+	"to-do-list-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers regroupe les contrôleurs de l'API et les dépôts dont ils dépendent
+type Handlers struct {
+	store *store.Store
+}
+
+// NewHandlers crée les contrôleurs adossés aux dépôts fournis
+func NewHandlers(s *store.Store) *Handlers {
+	return &Handlers{store: s}
+}
+
+// currentUser renvoie l'utilisateur injecté dans le contexte par le middleware AuthRequired.
+// En son absence, la réponse d'erreur est envoyée et nil est renvoyé
+func currentUser(c *gin.Context) *models.User {
+	authentifiedUser, exists := c.Get("currentUser")
+	if !exists {
+		apierror.Respond(c, apierror.CodeUnauthenticated, "auth.unauthenticated")
+		return nil
+	}
+	user, ok := authentifiedUser.(*models.User)
+	if !ok {
+		apierror.Respond(c, apierror.CodeInternal, "internal.current_user")
+		return nil
+	}
+	return user
+}
+
+// paramID lit le paramètre de route :id. En cas d'identifiant invalide, la réponse d'erreur est envoyée
+func paramID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInvalidRequest, "request.invalid_id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// bindView lit les paramètres fields et expand pour la ressource donnée (structure de réponse du package dto).
+// En cas de champ ou de relation inconnus, la réponse d'erreur est envoyée
+func bindView(c *gin.Context, resource any, expandable ...string) (dto.View, bool) {
+	var query dto.ViewQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RespondBindError(c, err)
+		return dto.View{}, false
+	}
+
+	view, err := dto.NewView(query, resource, expandable...)
+	if err != nil {
+		var unknown *dto.UnknownMemberError
+		if !errors.As(err, &unknown) {
+			apierror.Respond(c, apierror.CodeInternal, "internal.server")
+			return dto.View{}, false
+		}
+		fieldErr := apierror.Field(c, unknown.Param, "oneof", "validation.oneof", strings.Join(unknown.Allowed, ", "))
+		if len(unknown.Allowed) == 0 {
+			fieldErr = apierror.Field(c, unknown.Param, "oneof", "validation.not_expandable")
+		}
+		apierror.Respond(c, apierror.CodeValidationFailed, "request.invalid_fields", fieldErr)
+		return dto.View{}, false
+	}
+	return view, true
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+	"to-do-list-api/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Délai maximal accordé aux vérifications de la sonde de disponibilité
+const readinessTimeout = 2 * time.Second
+
+// Résultats d'une vérification de la sonde de disponibilité, stables et indépendants de la langue
+const (
+	checkOK          = "ok"
+	checkUnavailable = "unavailable"
+	checkSkipped     = "skipped"  // non exécutée, une vérification préalable ayant échoué
+	checkDraining    = "draining" // arrêt du serveur en cours
+)
+
+// Healthz indique que le processus répond
+func (h *Handlers) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz indique si le serveur peut recevoir du trafic. La sonde n'est pas authentifiée : le détail d'un échec est
+// journalisé, la réponse ne contient que son résultat
+func (h *Handlers) Readyz(c *gin.Context) {
+	if pkg.IsDraining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": gin.H{"server": checkDraining}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	ready := true
+	checks := gin.H{"database": checkOK, "migrations": checkOK}
+	if err := h.store.Health.Ping(ctx); err != nil {
+		ready = false
+		slog.ErrorContext(ctx, "base de données indisponible", "error", err)
+		checks["database"] = checkUnavailable
+		checks["migrations"] = checkSkipped
+	} else if err := h.store.Health.CheckSchema(ctx); err != nil {
+		ready = false
+		slog.ErrorContext(ctx, "schéma de la base de données non conforme", "error", err)
+		checks["migrations"] = checkUnavailable
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
+
+// Version renvoie les informations de build du serveur
+func (h *Handlers) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, pkg.GetBuildInfo())
+}
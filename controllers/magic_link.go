@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"to-do-list-api/apierror"
+	"to-do-list-api/dto"
+	"to-do-list-api/i18n"
+	"to-do-list-api/metrics"
+	"to-do-list-api/models"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMagicLink envoie un lien de connexion à usage unique à l'adresse indiquée, sans révéler si le compte existe
+func (h *Handlers) RequestMagicLink(c *gin.Context) {
+	var input dto.MagicLinkRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBindError(c, err)
+		return
+	}
+	input.Email = pkg.NormalizeEmail(input.Email)
+
+	//Limiter les demandes par email, que le compte existe ou non
+	if wait, blocked := pkg.MagicLinkRequestsByEmail.Blocked(input.Email); blocked {
+		tooManyLoginAttempts(c, wait)
+		return
+	}
+	pkg.MagicLinkRequestsByEmail.Fail(input.Email)
+
+	accepted := gin.H{"message": i18n.T(c.Request.Context(), "magic_link.sent")}
+
+	ctx := c.Request.Context()
+	user, err := h.store.Users.GetUserByEmail(ctx, input.Email)
+	if err != nil {
+		if err == store.ErrNotFound {
+			c.JSON(http.StatusAccepted, accepted)
+		} else {
+			apierror.Respond(c, apierror.CodeInternal, "internal.magic_link_request")
+		}
+		return
+	}
+	//Aucun lien pour un compte désactivé, sans le révéler
+	if user.IsDisabled() {
+		c.JSON(http.StatusAccepted, accepted)
+		return
+	}
+
+	//Le lien pointe vers la route de consommation de la version de l'API qui a reçu la demande
+	if err := h.sendMagicLink(c, user, "", c.FullPath()); err != nil {
+		slog.ErrorContext(ctx, "échec de l'envoi du lien de connexion", "user_id", user.ID, "error", err)
+		apierror.Respond(c, apierror.CodeInternal, "internal.magic_link_send")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, accepted)
+}
+
+// sendMagicLink enregistre un lien à usage unique pour user et l'envoie par email. Si email est renseigné, le lien
+// confirme le changement d'adresse et part à cette nouvelle adresse. routePath est le chemin de la route magic-link
+// de la version de l'API concernée. Un seul lien actif par utilisateur : les liens précédents non utilisés sont
+// supprimés, y compris un changement d'adresse non confirmé
+func (h *Handlers) sendMagicLink(c *gin.Context, user *models.User, email, routePath string) error {
+	ctx := c.Request.Context()
+	expiresAt := pkg.TimeNow().Add(pkg.MagicLinkTTL)
+	token, tokenHash := pkg.NewMagicLinkToken(expiresAt)
+	link := models.MagicLink{
+		TokenHash: tokenHash,
+		UserID:    user.ID,
+		Email:     email,
+		ExpiresAt: expiresAt,
+	}
+	if err := h.store.MagicLinks.ReplaceMagicLink(ctx, &link); err != nil {
+		return fmt.Errorf("enregistrement du lien : %w", err)
+	}
+
+	//Email rédigé dans la langue préférée du destinataire, à défaut dans celle de la requête
+	lang := i18n.FromContext(ctx)
+	if preferred, supported := i18n.Parse(user.Language); supported {
+		lang = preferred
+	}
+	linkURL := fmt.Sprintf("%s%s/consume?token=%s", pkg.MagicLinkBaseURL, routePath, url.QueryEscape(token))
+	mail := pkg.Mail{
+		To:      user.Email,
+		Subject: i18n.Translate(lang, "magic_link.mail_subject"),
+		Body:    i18n.Translate(lang, "magic_link.mail_body", user.Username, pkg.MagicLinkTTL, linkURL),
+	}
+	if email != "" {
+		mail.To = email
+		mail.Subject = i18n.Translate(lang, "user.email_change_mail_subject")
+		mail.Body = i18n.Translate(lang, "user.email_change_mail_body", user.Username, pkg.MagicLinkTTL, linkURL)
+	}
+	return pkg.DefaultMailer.Send(ctx, mail)
+}
+
+// Page de confirmation : les scanners d'emails qui préchargent les liens (GET) ne consomment pas le jeton
+var magicLinkConfirmPage = template.Must(template.New("magic-link").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head><meta charset="utf-8"><meta name="robots" content="noindex"><title>{{.Title}}</title></head>
+<body>
+<form method="post" action="{{.Action}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<input type="hidden" name="csrf_token" value="{{.CSRFToken}}">
+<button type="submit">{{.Button}}</button>
+</form>
+</body>
+</html>`))
+
+// ConfirmMagicLink affiche la page de confirmation d'un lien de connexion, sans le consommer
+func (h *Handlers) ConfirmMagicLink(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Robots-Tag", "noindex")
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	ctx := c.Request.Context()
+	page := struct{ Lang, Title, Button, Action, Token, CSRFToken string }{
+		Lang:      string(i18n.FromContext(ctx)),
+		Action:    c.Request.URL.Path, // la confirmation est envoyée à la même route, en POST
+		Title:     i18n.T(ctx, "magic_link.page_title"),
+		Button:    i18n.T(ctx, "magic_link.page_button"),
+		Token:     c.Query("token"),
+		CSRFToken: c.GetString("csrfToken"),
+	}
+	if err := magicLinkConfirmPage.Execute(c.Writer, page); err != nil {
+		slog.ErrorContext(c.Request.Context(), "échec du rendu de la page de confirmation", "error", err)
+	}
+}
+
+// ConsumeMagicLink consomme un lien de connexion et ouvre une session
+func (h *Handlers) ConsumeMagicLink(c *gin.Context) {
+	var input dto.ConsumeMagicLinkRequest
+	if err := c.ShouldBind(&input); err != nil {
+		apierror.RespondBindError(c, err)
+		return
+	}
+
+	tokenHash, err := pkg.ParseMagicLinkToken(input.Token)
+	if err != nil {
+		metrics.ObserveLogin(metrics.LoginMagicLink, metrics.LoginFailure)
+		apierror.Respond(c, apierror.CodeMagicLinkInvalid, "magic_link.invalid")
+		return
+	}
+
+	ctx := c.Request.Context()
+	link, err := h.store.MagicLinks.GetMagicLink(ctx, tokenHash)
+	if err != nil {
+		if err == store.ErrNotFound {
+			metrics.ObserveLogin(metrics.LoginMagicLink, metrics.LoginFailure)
+			apierror.Respond(c, apierror.CodeMagicLinkInvalid, "magic_link.invalid")
+		} else {
+			apierror.Respond(c, apierror.CodeInternal, "internal.magic_link_check")
+		}
+		return
+	}
+
+	//Marquer le lien comme utilisé de façon atomique : seule la première consommation réussit
+	consumed, err := h.store.MagicLinks.ConsumeMagicLink(ctx, link.ID, pkg.TimeNow())
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.magic_link_check")
+		return
+	}
+	if !consumed {
+		metrics.ObserveLogin(metrics.LoginMagicLink, metrics.LoginFailure)
+		apierror.Respond(c, apierror.CodeMagicLinkInvalid, "magic_link.invalid")
+		return
+	}
+
+	//Lien de changement d'adresse : sa consommation prouve la possession de la nouvelle adresse, qui remplace l'ancienne
+	message := "auth.logged_in"
+	if link.Email != "" {
+		link.User.Email = link.Email
+		if err := h.store.Users.UpdateUser(ctx, &link.User); err != nil {
+			if errors.Is(err, store.ErrDuplicate) {
+				apierror.Respond(c, apierror.CodeEmailTaken, "user.email_taken")
+			} else {
+				apierror.Respond(c, apierror.CodeInternal, "internal.email_change")
+			}
+			return
+		}
+		message = "user.email_changed"
+	}
+
+	if _, err := h.startSession(c, &link.User); err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	pkg.MagicLinkRequestsByEmail.Reset(link.User.Email)
+	metrics.ObserveLogin(metrics.LoginMagicLink, metrics.LoginSuccess)
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c.Request.Context(), message)})
+}
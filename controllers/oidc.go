@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"to-do-list-api/apierror"
+	"to-do-list-api/i18n"
+	"to-do-list-api/metrics"
+	"to-do-list-api/models"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+)
+
+// Cookie conservant l'état du flux d'autorisation entre la redirection et le callback
+const oidcFlowCookie = "oidc_flow"
+
+// oidcFlow contient les valeurs à retrouver au retour du fournisseur
+type oidcFlow struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"` // code_verifier PKCE
+}
+
+// OIDCLogin redirige vers le fournisseur d'identité pour démarrer une connexion OpenID Connect
+func (h *Handlers) OIDCLogin(c *gin.Context) {
+	provider, exists := pkg.OIDCProviders[c.Param("provider")]
+	if !exists {
+		apierror.Respond(c, apierror.CodeOIDCProviderUnknown, "oidc.provider_unknown")
+		return
+	}
+
+	flow := oidcFlow{
+		Provider: provider.Config.Name,
+		State:    pkg.GenerateToken(),
+		Nonce:    pkg.GenerateToken(),
+		Verifier: oauth2.GenerateVerifier(),
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), flow.State, flow.Nonce, flow.Verifier)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "échec de la préparation de la connexion OIDC", "provider", provider.Config.Name, "error", err)
+		apierror.Respond(c, apierror.CodeOIDCProviderUnavailable, "oidc.provider_unavailable")
+		return
+	}
+
+	encodedFlow, err := json.Marshal(flow)
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.oidc_prepare")
+		return
+	}
+
+	//Le cookie doit accompagner la redirection de retour du fournisseur : SameSite=Lax
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookie, base64.RawURLEncoding.EncodeToString(encodedFlow), 600, oidcCookiePath(provider), "", pkg.CookieSecure, true)
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback termine une connexion OpenID Connect : vérification de l'ID token, liaison ou création du compte
+// et ouverture d'une session
+func (h *Handlers) OIDCCallback(c *gin.Context) {
+	provider, exists := pkg.OIDCProviders[c.Param("provider")]
+	if !exists {
+		apierror.Respond(c, apierror.CodeOIDCProviderUnknown, "oidc.provider_unknown")
+		return
+	}
+
+	//Récupérer puis effacer l'état du flux
+	flow, err := readOIDCFlow(c)
+	c.SetCookie(oidcFlowCookie, "", -1, oidcCookiePath(provider), "", pkg.CookieSecure, true)
+	if err != nil || flow.Provider != provider.Config.Name {
+		apierror.Respond(c, apierror.CodeOIDCFlowInvalid, "oidc.flow_invalid")
+		return
+	}
+
+	//Vérifier que la réponse correspond bien à la demande émise (protection CSRF)
+	if subtle.ConstantTimeCompare([]byte(c.Query("state")), []byte(flow.State)) != 1 {
+		apierror.Respond(c, apierror.CodeOIDCStateInvalid, "oidc.state_invalid")
+		return
+	}
+
+	if providerError := c.Query("error"); providerError != "" {
+		metrics.ObserveLogin(metrics.LoginOIDC, metrics.LoginFailure)
+		apierror.Respondf(c, apierror.CodeOIDCDenied, "oidc.denied", providerError)
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		apierror.Respond(c, apierror.CodeInvalidRequest, "oidc.code_missing")
+		return
+	}
+
+	claims, err := provider.Exchange(c.Request.Context(), code, flow.Verifier, flow.Nonce)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "échec de l'échange du code d'autorisation OIDC", "provider", provider.Config.Name, "error", err)
+		metrics.ObserveLogin(metrics.LoginOIDC, metrics.LoginFailure)
+		apierror.Respond(c, apierror.CodeOIDCAuthenticationFailed, "oidc.authentication_failed")
+		return
+	}
+
+	user, err := h.resolveExternalIdentity(c.Request.Context(), provider.Config, claims)
+	if err != nil {
+		var apiErr *apierror.Error
+		if errors.As(err, &apiErr) && apiErr.Code != apierror.CodeInternal {
+			metrics.ObserveLogin(metrics.LoginOIDC, metrics.LoginFailure)
+		}
+		apierror.RespondError(c, err)
+		return
+	}
+
+	if _, err := h.startSession(c, user); err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	metrics.ObserveLogin(metrics.LoginOIDC, metrics.LoginSuccess)
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c.Request.Context(), "auth.logged_in")})
+}
+
+// oidcCookiePath restreint le cookie d'état au répertoire de l'URL de retour du fournisseur, quel que soit le préfixe
+// de version sous lequel la connexion a été demandée
+func oidcCookiePath(provider *pkg.OIDCProvider) string {
+	redirectURL, err := url.Parse(provider.Config.RedirectURL)
+	if err != nil || redirectURL.Path == "" {
+		return "/"
+	}
+	return path.Dir(redirectURL.Path)
+}
+
+// readOIDCFlow décode le cookie d'état du flux d'autorisation
+func readOIDCFlow(c *gin.Context) (*oidcFlow, error) {
+	raw, err := c.Cookie(oidcFlowCookie)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var flow oidcFlow
+	if err := json.Unmarshal(decoded, &flow); err != nil {
+		return nil, err
+	}
+	return &flow, nil
+}
+
+// resolveExternalIdentity retrouve l'utilisateur lié à l'identité externe, le rattache à un compte existant
+// ou le crée selon la configuration du fournisseur. Les erreurs renvoyées sont des *apierror.Error
+func (h *Handlers) resolveExternalIdentity(ctx context.Context, cfg pkg.OIDCProviderConfig, claims *pkg.OIDCClaims) (*models.User, error) {
+	//Identité déjà liée
+	identity, err := h.store.Identities.GetIdentity(ctx, cfg.Name, claims.Subject)
+	if err == nil {
+		return &identity.User, nil
+	}
+	if err != store.ErrNotFound {
+		return nil, apierror.New(apierror.CodeInternal, "internal.identity_lookup")
+	}
+
+	claims.Email = pkg.NormalizeEmail(claims.Email)
+	if claims.Email == "" || !pkg.ValidateEmailFormat(claims.Email) {
+		return nil, apierror.New(apierror.CodeOIDCEmailMissing, "oidc.email_missing")
+	}
+
+	user, err := h.store.Users.GetUserByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		//Rattachement à un compte existant, uniquement si l'email est vérifié par le fournisseur
+		if !cfg.LinkVerifiedEmail || !claims.EmailVerified {
+			return nil, apierror.New(apierror.CodeOIDCEmailTaken, "oidc.email_taken")
+		}
+	case err == store.ErrNotFound:
+		if !cfg.AllowSignup {
+			return nil, apierror.New(apierror.CodeOIDCAccountNotLinked, "oidc.account_not_linked")
+		}
+		if user, err = h.createExternalUser(ctx, claims); err != nil {
+			return nil, apierror.New(apierror.CodeInternal, "internal.account_create")
+		}
+	default:
+		return nil, apierror.New(apierror.CodeInternal, "internal.account_lookup")
+	}
+
+	identity = &models.ExternalIdentity{
+		Provider: cfg.Name,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+		UserID:   user.ID,
+	}
+	if err := h.store.Identities.CreateIdentity(ctx, identity); err != nil {
+		return nil, apierror.New(apierror.CodeInternal, "internal.identity_link")
+	}
+
+	return user, nil
+}
+
+// Caractères interdits dans un username
+var usernameForbiddenChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// createExternalUser crée un compte à partir des claims du fournisseur, avec un mot de passe aléatoire inutilisable
+func (h *Handlers) createExternalUser(ctx context.Context, claims *pkg.OIDCClaims) (*models.User, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameForbiddenChars.ReplaceAllString(base, "")
+	if len(base) > 16 {
+		base = base[:16]
+	}
+	if len(base) < 3 {
+		base = "user" + base
+	}
+
+	//Trouver un username libre en ajoutant un suffixe numérique si nécessaire
+	username := base
+	for suffix := 2; ; suffix++ {
+		_, err := h.store.Users.GetUserByUsername(ctx, username)
+		if err == store.ErrNotFound {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if suffix > 999 {
+			return nil, fmt.Errorf("aucun username disponible pour %q", base)
+		}
+		username = fmt.Sprintf("%s%d", base, suffix)
+	}
+
+	hashedPassword, err := pkg.HashPassword(pkg.GenerateToken())
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username: username,
+		Email:    claims.Email,
+		Password: hashedPassword,
+	}
+	if err := h.store.Users.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
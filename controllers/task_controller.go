@@ -1,206 +1,164 @@
 package controllers
 
 import (
-	"fmt"
-	"log"
 	"net/http"
-	"regexp"
 	"strings"
+	"to-do-list-api/apierror"
+	"to-do-list-api/dto"
+	"to-do-list-api/i18n"
 	"to-do-list-api/models"
-	"to-do-list-api/pkg"
+	"to-do-list-api/store"
 
 	"github.com/gin-gonic/gin"
 )
 
-// Liste des statuts valides
-var validStatUses = map[string]bool{
-	"to-do":       true,
-	"in-progress": true,
-	"done":        true,
-}
+// GetTasks permet de récupérer la liste des tâches de l'utilisateur authentifié
+func (h *Handlers) GetTasks(c *gin.Context) {
+	user := currentUser(c)
+	if user == nil {
+		return
+	}
 
-// GetTasks godoc
-// @Summary Récupère les tâches
-// @Description Récupère la liste des tâches, avec une option pour filtrer par statut
-// @Tags Tasks
-// @Produce json
-// @Param status query string false "Filtrer par statut ('to-do', 'in-progress', 'done')"
-// @Success 200 {object} map[string][]models.Task "Liste des tâches"
-// @Failure 400 {object} map[string]string{"error": "Description de l'erreur"}
-// @Failure 500 {object} map[string]string{"error": "Description de l'erreur"}
-// @Router /tasks [get]
-
-// GetTasks permet de récupérer la liste des tâches
-func GetTasks(c *gin.Context) {
-	var tasks []models.Task
-	status := c.Query("status") //paramètre de filtrage
-
-	query := pkg.DB
-	if status != "" {
-		if !validStatUses[status] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Statut invalide. Options : 'to-do', 'in-progress', 'done'"})
-			return
-		}
-		query = query.Where("status = ?", status)
+	var query dto.TaskListQuery //paramètres de filtrage
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.RespondBindError(c, err)
+		return
+	}
+	view, ok := bindView(c, dto.TaskResponse{}, "user")
+	if !ok {
+		return
 	}
 
-	if err := query.Find(&tasks).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des tâches"})
+	tasks, err := h.store.Tasks.ListTasks(c.Request.Context(), store.TaskFilter{UserID: user.ID, Status: query.Status, Tag: dto.NormalizeTag(query.Tag)})
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.task_list")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+	//Toutes les tâches appartiennent à l'utilisateur authentifié : il est inclus tel quel si la relation est développée
+	var owner *models.User
+	if view.Expands("user") {
+		owner = user
+	}
+	c.JSON(http.StatusOK, gin.H{"tasks": view.Render(dto.NewTaskResponses(tasks, owner))})
 
 }
 
-// CreateTask godoc
-// @Summary Crée une nouvelle tâche
-// @Description Ajoute une tâche pour un utilisateur spécifique
-// @Tags Tasks
-// @Accept json
-// @Produce json
-// @Param payload body models.Task true "Détails de la tâche"
-// @Success 201 {object} map[string]string{"message": "Tâche créée avec succès"}
-// @Failure 400 {object} map[string]string{"error": "Description de l'erreur"}
-// @Failure 500 {object} map[string]string{"error": "Description de l'erreur"}
-// @Router /tasks [post]
-
 // CreateTask permet de créer une tâche
-func CreateTask(c *gin.Context) {
-	var task models.Task
-	query := pkg.DB
-
-	// Lier les données de la requête au modèle Task
-	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format des données invalides"})
+func (h *Handlers) CreateTask(c *gin.Context) {
+	user := currentUser(c)
+	if user == nil {
 		return
 	}
 
-	//Vérifier que le user associé est existant
-	var user models.User
-
-	if err := query.First(&user, task.UserID).Error; err != nil {
-		log.Printf("Id user associé %d\n", task.UserID)
-		log.Println(err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Utilisateur associé introuvable"})
+	// Lire et valider les données de la requête (titre, statut et étiquettes)
+	var input dto.CreateTaskRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBindError(c, err)
 		return
 	}
 
-	//Vérifier que le titre est saisi
-	if task.Title == "" || task.Title == " " {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Le titre est requis"})
-		return
+	//La tâche appartient toujours à l'utilisateur authentifié
+	task := models.Task{
+		Title:  strings.TrimSpace(input.Title),
+		Status: input.Status,
+		UserID: user.ID,
+		Tags:   dto.NormalizeTags(input.Tags),
 	}
-
-	// Vérifier que le statut est valide
-	if task.Status == "" || task.Status == " " || !validStatUses[task.Status] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Statut invalide. Options : 'to-do', 'in-progress', 'done'"})
-		return
+	if input.RemindAt != nil {
+		remindAt := input.RemindAt.UTC()
+		task.RemindAt = &remindAt
 	}
 
 	// Enregistrer la tâche dans la base de données
-	if err := query.Create(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création de la tâche"})
+	if err := h.store.Tasks.CreateTask(c.Request.Context(), &task); err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.task_create")
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"message": fmt.Sprintf("Tâche %s créée et associée au user %s avec succès", task.Title, user.Username)})
+	c.JSON(http.StatusCreated, gin.H{"message": i18n.T(c.Request.Context(), "task.created", task.Title, user.Username), "task": dto.NewTaskResponse(&task, nil)})
 
 }
 
-// UpdateTask godoc
-// @Summary Met à jour une tâche existante
-// @Description Modifie le titre ou le statut d'une tâche
-// @Tags Tasks
-// @Accept json
-// @Produce json
-// @Param id path int true "ID de la tâche"
-// @Param payload body models.Task true "Détails de la mise à jour"
-// @Success 200 {object} map[string]string{"message": "Tâche mise à jour avec succès"}
-// @Failure 400 {object} map[string]string{"error": "Description de l'erreur"}
-// @Failure 500 {object} map[string]string{"error": "Description de l'erreur"}
-// @Router /tasks/{id} [put]
-
 // UpdateTask permet de mettre à jour une tâche
-func UpdateTask(c *gin.Context) {
-	titleRegex := regexp.MustCompile(`^[\p{L}0-9\s]{4,}$`) // p{L} : pour autoriser tout caractère alphabétique (accentué ou non) dans le titre
-	query := pkg.DB
-
+func (h *Handlers) UpdateTask(c *gin.Context) {
 	// Récupérer la tâche ajoutée au contexte par le middleware
 	taskFromContext, exists := c.Get("task")
 	if !exists {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Impossible de récupérer la tâche depuis le contexte"})
+		apierror.Respond(c, apierror.CodeInternal, "internal.task_from_context")
 		return
 	}
 
 	//Convertir le type de la tâche récupérée
 	task, ok := taskFromContext.(*models.Task)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Impossible de convertir le type de la tâche récupérée depuis le contexte"})
+		apierror.Respond(c, apierror.CodeInternal, "internal.task_type")
 		return
 	}
 
-	var updatedTask models.Task
+	view, ok := bindView(c, dto.TaskResponse{}, "user")
+	if !ok {
+		return
+	}
+	//Le middleware AuthorizeTaskOwnerShip garantit que la tâche appartient à l'utilisateur authentifié
+	var owner *models.User
+	if view.Expands("user") {
+		if owner = currentUser(c); owner == nil {
+			return
+		}
+	}
 
-	//Récupérer les données du corps
+	var updatedTask dto.UpdateTaskRequest
+
+	//Récupérer et valider les données du corps
 	if err := c.ShouldBindJSON(&updatedTask); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBindError(c, err)
 		return
 	}
 
 	//Empêcher la modification de l'id du user associé
-	if updatedTask.UserID != task.UserID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Action non autorisée"})
+	if updatedTask.UserID != nil && *updatedTask.UserID != task.UserID {
+		apierror.Invalid(c, "user_id", "immutable", "validation.task_owner_immutable")
 		return
 	}
 
-	//Mettre à jour les champs de la tâche (title et status)
-	if updatedTask.Status != task.Status { //vérifier la validité de Status si modifié
-		if !validStatUses[updatedTask.Status] {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Statut invalide. Options : 'to-do', 'in-progress', 'done'"})
-			return
-		}
-		task.Status = updatedTask.Status
+	//Mettre à jour les champs fournis (title, status, tags et remind_at)
+	if updatedTask.Status != nil {
+		task.Status = *updatedTask.Status
 	}
-	if updatedTask.Title != task.Title { //vérifier le format de Title si modifié
-		if !titleRegex.MatchString(updatedTask.Title) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Le titre doit comporter au moins 4 caractères alphanumériques."})
-			return
+	if updatedTask.Title != nil {
+		task.Title = strings.TrimSpace(*updatedTask.Title) //Nettoyer les espaces en excès avant de mettre à jour
+	}
+	if updatedTask.Tags != nil {
+		task.Tags = dto.NormalizeTags(*updatedTask.Tags)
+	}
+	if updatedTask.RemindAt.Set {
+		//Un nouveau rappel sera envoyé à la nouvelle date, même si le précédent est déjà parti
+		task.RemindAt, task.RemindedAt, task.ReminderAttempts = nil, nil, 0
+		if updatedTask.RemindAt.Time != nil {
+			remindAt := updatedTask.RemindAt.Time.UTC()
+			task.RemindAt = &remindAt
 		}
-
-		updatedTask.Title = strings.TrimSpace(updatedTask.Title) //Nettoyer les espaces en excès avant de mettre à jour
-		task.Title = updatedTask.Title
 	}
 
-	if err := query.Save(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la mise à jour de la tâche"})
+	if err := h.store.Tasks.UpdateTask(c.Request.Context(), task); err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.task_update")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Tâche mis à jour avec succès", "task": task})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c.Request.Context(), "task.updated"), "task": view.Render(dto.NewTaskResponse(task, owner))})
 }
 
-// DeleteTask godoc
-// @Summary Supprime une tâche
-// @Description Supprime une tâche spécifique par son ID
-// @Tags Tasks
-// @Produce json
-// @Param id path int true "ID de la tâche"
-// @Success 200 {object} map[string]string{"message": "Tâche supprimée avec succès"}
-// @Failure 400 {object} map[string]string{"error": "Description de l'erreur"}
-// @Failure 500 {object} map[string]string{"error": "Description de l'erreur"}
-// @Router /tasks/{id} [delete]
-
-// DeleteTask permet de supprimer une tâche
-func DeleteTask(c *gin.Context) {
+// DeleteTask permet de supprimer une tâche : elle est mise à la corbeille, puis purgée par la tâche de fond trash-purge
+func (h *Handlers) DeleteTask(c *gin.Context) {
 	// Récupérer la tâche depuis le contexte
 	task, _ := c.Get("task")
 	castedTask := task.(*models.Task)
 
 	//Supprimer la tâche
-	if err := pkg.DB.Unscoped().Delete(castedTask).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la suppression de la tâche"})
+	if err := h.store.Tasks.DeleteTask(c.Request.Context(), castedTask.ID); err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.task_delete")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Tâche supprimée avec succès"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(c.Request.Context(), "task.deleted")})
 }
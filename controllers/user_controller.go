@@ -1,251 +1,252 @@
 package controllers
 
 import (
-	"fmt"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-	"strconv"
+	"strings"
+	"to-do-list-api/apierror"
+	"to-do-list-api/dto"
+	"to-do-list-api/i18n"
 	"to-do-list-api/models"
 	"to-do-list-api/pkg"
+	"to-do-list-api/store"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // CreateUser permet de créer un nouvel utilisateur
-func CreateUser(c *gin.Context) {
-	var user models.User
+func (h *Handlers) CreateUser(c *gin.Context) {
+	ctx := c.Request.Context()
 
-	query := pkg.DB
-
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Données invalides"})
+	var input dto.CreateUserRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		apierror.RespondBindError(c, err)
 		return
 	}
-
-	//Vérifier que username et email sont non nuls
-	if user.Username == "" || user.Email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Le username et l'email sont requis"})
-		return
+	user := models.User{
+		Username: input.Username,
+		Email:    pkg.NormalizeEmail(input.Email),
+		Language: normalizeLanguage(input.Language),
 	}
 
 	//Vérification de l'unicité du username
-	var existingUser models.User
-
-	if err := query.Where("username = ?", user.Username).First(&existingUser).Error; err != nil {
-		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne lors de la vérification du username"})
+	if _, err := h.store.Users.GetUserByUsername(ctx, user.Username); err != nil {
+		if err != store.ErrNotFound {
+			apierror.Respond(c, apierror.CodeInternal, "internal.username_check")
 			return
 		}
 
 	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Ce username est déjà utilisé"})
-		return
-	}
-
-	//Vérification du format du user
-	if !pkg.ValidateUsernameFormat(user.Username) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format du username invalide"})
-		return
-	}
-
-	//Vérification du format de l'email
-	if !pkg.ValidateEmailFormat(user.Email) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format d'email invalide"})
+		apierror.Respond(c, apierror.CodeUsernameTaken, "user.username_taken")
 		return
 	}
 
 	//Vérification de l'unicité de l'email
-	if err := query.Where("email = ?", user.Email).First(&existingUser).Error; err != nil {
-		if err != gorm.ErrRecordNotFound {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne lors de la vérification de l'email"})
+	if _, err := h.store.Users.GetUserByEmail(ctx, user.Email); err != nil {
+		if err != store.ErrNotFound {
+			apierror.Respond(c, apierror.CodeInternal, "internal.email_check")
 			return
 		}
 	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cet email est déjà utilisé"})
+		apierror.Respond(c, apierror.CodeEmailTaken, "user.email_taken")
+		return
+	}
+
+	//Hachage du mot de passe
+	hashedPassword, err := pkg.HashPassword(input.Password)
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.password_hash")
 		return
 	}
+	user.Password = hashedPassword
 
-	if err := query.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la création du user"})
+	if err := h.store.Users.CreateUser(ctx, &user); err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			apierror.Respond(c, apierror.CodeAccountExists, "user.account_exists")
+		} else {
+			apierror.Respond(c, apierror.CodeInternal, "internal.user_create")
+		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": fmt.Sprintf("User %s créé avec succès", user.Username), "user": user})
+	c.JSON(http.StatusCreated, gin.H{"message": i18n.T(ctx, "user.created", user.Username), "user": dto.NewUserResponse(&user)})
 }
 
 // GetUser permet de récupérer un utilisateur par son ID
-func GetUser(c *gin.Context) {
-	id := c.Param("id")
-	query := pkg.DB
-	var user models.User
+func (h *Handlers) GetUser(c *gin.Context) {
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
 
-	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "L'ID doit être un entier valide"})
+	view, ok := bindView(c, dto.UserResponse{})
+	if !ok {
 		return
 	}
 
-	if err := query.First(&user, id).Error; err != nil {
-		if err := query.First(&user, id).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Utilisateur introuvable"})
-			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération de l'utilisateur"})
-			}
-			return
+	user, err := h.store.Users.GetUser(c.Request.Context(), id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			apierror.Respond(c, apierror.CodeUserNotFound, "user.not_found")
+		} else {
+			apierror.Respond(c, apierror.CodeInternal, "internal.user_get")
 		}
-
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	c.JSON(http.StatusOK, gin.H{"user": view.Render(dto.NewUserResponse(user))})
 
 }
 
-// GetUsers godoc
-// @Summary Récupère tous les utilisateurs
-// @Description Liste tous les utilisateurs existants
-// @Tags Users
-// @Produce json
-// @Success 200 {object} map[string][]models.User "Liste des utilisateurs"
-// @Failure 500 {object} map[string]string{"error": "Description de l'erreur"}
-// @Router /users [get]
-
 // GetUsers permet de récupérer tous les utilisateurs
-func GetUsers(c *gin.Context) {
-	var users []models.User
-	query := pkg.DB
+func (h *Handlers) GetUsers(c *gin.Context) {
+	view, ok := bindView(c, dto.UserResponse{})
+	if !ok {
+		return
+	}
 
-	if err := query.Find(&users).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Utilisateurs introuvables"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération des users"})
-		}
+	users, err := h.store.Users.ListUsers(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.user_list")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"users": users})
+	c.JSON(http.StatusOK, gin.H{"users": view.Render(dto.NewUserResponses(users))})
 }
 
 // UpdateUser permet de mettre à jour les informations d'un utilisateur
-func UpdateUser(c *gin.Context) {
-	id := c.Param("id")
-	query := pkg.DB
-
-	var user models.User
-
-	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "L'ID doit être un entier valide"})
+func (h *Handlers) UpdateUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, ok := paramID(c)
+	if !ok {
 		return
 	}
 
 	//Vérification de l'existence de l'utilisateur à mettre à jour dans la base de données
-	if err := query.First(&user, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Utilisateur introuvable"})
+	user, err := h.store.Users.GetUser(ctx, id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			apierror.Respond(c, apierror.CodeUserNotFound, "user.not_found")
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne lors de la récupération du user"})
+			apierror.Respond(c, apierror.CodeInternal, "internal.user_get_for_update")
 		}
 		return
 	}
 
-	var updatedUserData models.User
+	var updatedUserData dto.UpdateUserRequest
 
-	//Lecture des données envoyées
+	//Lecture et validation des données envoyées (champs obligatoires, format du username et de l'email)
 	if err := c.ShouldBindJSON(&updatedUserData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format des données invalide"})
-		return
-	}
-
-	// Vérification des champs obligatoires
-	if updatedUserData.Username == "" || updatedUserData.Email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Le username et l'email sont requis"})
-		return
-	}
-
-	//Vérification du format du username
-	if !pkg.ValidateUsernameFormat(updatedUserData.Username) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format du username invalide"})
-		return
-	}
-
-	//Vérification du format de l'email
-	if !pkg.ValidateEmailFormat(updatedUserData.Email) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Format d'email invalide"})
+		apierror.RespondBindError(c, err)
 		return
 	}
+	updatedUserData.Email = pkg.NormalizeEmail(updatedUserData.Email)
 
 	//Vérification de l'unicité du username (si modifié)
 	if updatedUserData.Username != user.Username {
-		log.Printf("Mise à jour du username:%s", updatedUserData.Username)
-		log.Printf("username:%s", user.Username)
-		fmt.Printf("Param ID: %s, Database User ID: %d\n", id, user.ID)
+		slog.DebugContext(ctx, "changement de username", "user_id", user.ID, "old_username", user.Username, "new_username", updatedUserData.Username)
 
-		var existingUser models.User
-
-		if err := query.Debug().Where("username = ? AND id != ?", updatedUserData.Username, user.ID).First(&existingUser).Error; err != nil {
-			if err != gorm.ErrRecordNotFound {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne lors de la vérification de l'unicité du username"})
+		if existingUser, err := h.store.Users.GetUserByUsername(ctx, updatedUserData.Username); err != nil {
+			if err != store.ErrNotFound {
+				apierror.Respond(c, apierror.CodeInternal, "internal.username_unique_check")
 				return
 			}
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Ce username est déjà utilisé"})
+		} else if existingUser.ID != user.ID {
+			apierror.Respond(c, apierror.CodeUsernameTaken, "user.username_taken")
 			return
 		}
+	}
 
-		//Vérification de l'unicité de l'email (si modifié)
-		if err := query.Where("email = ? AND id != ?", updatedUserData.Email, user.ID).First(&existingUser).Error; err != nil {
-			if err != gorm.ErrRecordNotFound {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne lors de la vérification de l'unicité du username"})
+	//Vérification de l'unicité de l'email (si modifié)
+	if updatedUserData.Email != user.Email {
+		if existingUser, err := h.store.Users.GetUserByEmail(ctx, updatedUserData.Email); err != nil {
+			if err != store.ErrNotFound {
+				apierror.Respond(c, apierror.CodeInternal, "internal.username_unique_check")
 				return
 			}
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Cet email est déjà utilisé"})
+		} else if existingUser.ID != user.ID {
+			apierror.Respond(c, apierror.CodeEmailTaken, "user.email_taken")
 			return
 		}
+	}
+
+	//Mise à jour des données de l'utilisateur ; une nouvelle adresse n'est enregistrée qu'après confirmation
+	user.Username = updatedUserData.Username
 
-		//Mise à jour des données de l'utilisateur
-		user.Username = updatedUserData.Username
-		user.Email = updatedUserData.Email
+	//Sauvegarder dans la base de données
+	if err := h.store.Users.UpdateUser(ctx, user); err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.user_update")
+		return
+	}
 
-		//Sauvegarder dans la base de données
-		if err := query.Save(&user).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne lors de la sauvegarde des mises à jour de l'utilisateur"})
+	//Le lien de confirmation part à la nouvelle adresse : sans lui, un changement d'email suivi d'une demande de lien
+	//de connexion suffirait à prendre le contrôle du compte
+	if updatedUserData.Email != user.Email {
+		routePath := strings.TrimSuffix(c.FullPath(), "/users/:id") + "/auth/magic-link"
+		if err := h.sendMagicLink(c, user, updatedUserData.Email, routePath); err != nil {
+			slog.ErrorContext(ctx, "échec de l'envoi de la confirmation d'adresse", "user_id", user.ID, "error", err)
+			apierror.Respond(c, apierror.CodeInternal, "internal.email_change")
 			return
 		}
-
-		//Envoyer une réponse au client
-		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("User %s mis à jour avec succès", user.Username)})
+		c.JSON(http.StatusOK, gin.H{"message": i18n.T(ctx, "user.email_change_pending", user.Username, updatedUserData.Email)})
+		return
 	}
+
+	//Envoyer une réponse au client
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(ctx, "user.updated", user.Username)})
 }
 
 // DeleteUser permet de supprimer un utilisateur
-func DeleteUser(c *gin.Context) {
-	id := c.Param("id")
-	query := pkg.DB
-
-	if _, err := strconv.Atoi(id); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "L'id doit être un entier valide"})
+func (h *Handlers) DeleteUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, ok := paramID(c)
+	if !ok {
 		return
 	}
 
-	var user models.User
-
 	//Récupérer le user à supprimer
-	if err := query.First(&user, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Utilisateur introuvable"})
+	user, err := h.store.Users.GetUser(ctx, id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			apierror.Respond(c, apierror.CodeUserNotFound, "user.not_found")
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la récupération de l'utilisateur à supprimer"})
+			apierror.Respond(c, apierror.CodeInternal, "internal.user_get_for_delete")
 		}
 		return
 	}
 
 	// Supprimer l'utilisateur correspondant
-	if err := query.Unscoped().Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur lors de la suppression de l'utilisateur"})
+	if err := h.store.Users.DeleteUser(ctx, user.ID); err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.user_delete")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(ctx, "user.deleted", user.Username)})
+}
+
+// UnlockUser permet à un administrateur de déverrouiller un compte
+func (h *Handlers) UnlockUser(c *gin.Context) {
+	ctx := c.Request.Context()
+	id, ok := paramID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.store.Users.GetUser(ctx, id)
+	if err != nil {
+		if err == store.ErrNotFound {
+			apierror.Respond(c, apierror.CodeUserNotFound, "user.not_found")
+		} else {
+			apierror.Respond(c, apierror.CodeInternal, "internal.user_get")
+		}
+		return
+	}
+
+	if err := h.store.Users.SetLoginFailures(ctx, user.ID, 0, nil); err != nil {
+		apierror.Respond(c, apierror.CodeInternal, "internal.user_unlock")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Utilisateur %s supprimé avec succès", user.Username)})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(ctx, "user.unlocked", user.Username)})
 }
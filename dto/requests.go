@@ -0,0 +1,110 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+	"to-do-list-api/models"
+)
+
+// RegisterRequest est le corps de POST /auth/register
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,username"`
+	Email    string `json:"email" binding:"required,max=50,emailaddr"`
+	Password string `json:"password" binding:"required,max=128"`
+	Language string `json:"language" binding:"omitempty,language"`
+}
+
+func (r RegisterRequest) PasswordCandidate() (string, string, []string) {
+	return "password", r.Password, []string{r.Username, r.Email}
+}
+
+// LoginRequest est le corps de POST /auth/login
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,emailaddr"`
+	Password string `json:"password" binding:"required,max=128"`
+}
+
+// ChangePasswordRequest est le corps de PUT /auth/password. Account, renseigné par le contrôleur avant la lecture du
+// corps, est le compte dont le nouveau mot de passe ne doit pas reprendre le username ou l'email
+type ChangePasswordRequest struct {
+	CurrentPassword string       `json:"current_password" binding:"required,max=128"`
+	NewPassword     string       `json:"new_password" binding:"required,max=128"`
+	Account         *models.User `json:"-" binding:"-"`
+}
+
+func (r ChangePasswordRequest) PasswordCandidate() (string, string, []string) {
+	var userInputs []string
+	if r.Account != nil {
+		userInputs = []string{r.Account.Username, r.Account.Email}
+	}
+	return "new_password", r.NewPassword, userInputs
+}
+
+// ChangeLanguageRequest est le corps de PUT /auth/language. Une langue vide efface la préférence
+type ChangeLanguageRequest struct {
+	Language string `json:"language" binding:"omitempty,language"`
+}
+
+// MagicLinkRequest est le corps de POST /auth/magic-link
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,emailaddr"`
+}
+
+// ConsumeMagicLinkRequest est le formulaire (ou le corps JSON) de POST /auth/magic-link/consume
+type ConsumeMagicLinkRequest struct {
+	Token string `form:"token" json:"token" binding:"required"`
+}
+
+// CreateUserRequest est le corps de POST /users. Le statut d'administrateur ne s'attribue pas via l'API
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required,username"`
+	Email    string `json:"email" binding:"required,emailaddr"`
+	Password string `json:"password" binding:"required,max=128"`
+	Language string `json:"language" binding:"omitempty,language"`
+}
+
+func (r CreateUserRequest) PasswordCandidate() (string, string, []string) {
+	return "password", r.Password, []string{r.Username, r.Email}
+}
+
+// UpdateUserRequest est le corps de PUT /users/{id}
+type UpdateUserRequest struct {
+	Username string `json:"username" binding:"required,username"`
+	Email    string `json:"email" binding:"required,emailaddr"`
+}
+
+// TaskListQuery regroupe les paramètres de GET /tasks
+type TaskListQuery struct {
+	Status string `form:"status" binding:"omitempty,taskstatus"`
+	Tag    string `form:"tag" binding:"omitempty,tag"`
+}
+
+// CreateTaskRequest est le corps de POST /tasks. La tâche appartient toujours à l'utilisateur authentifié
+type CreateTaskRequest struct {
+	Title    string     `json:"title" binding:"required,title"`
+	Status   string     `json:"status" binding:"required,taskstatus"`
+	Tags     []string   `json:"tags" binding:"omitempty,tags"`
+	RemindAt *time.Time `json:"remind_at"`
+}
+
+// UpdateTaskRequest est le corps de PUT /tasks/{id}. Les champs absents restent inchangés ; tags remplace l'ensemble des
+// étiquettes ; remind_at à null supprime le rappel ; user_id, accepté pour compatibilité, doit désigner le propriétaire
+// actuel
+type UpdateTaskRequest struct {
+	Title    *string      `json:"title" binding:"omitempty,title"`
+	Status   *string      `json:"status" binding:"omitempty,taskstatus"`
+	Tags     *[]string    `json:"tags" binding:"omitempty,tags"`
+	RemindAt NullableTime `json:"remind_at"`
+	UserID   *uint        `json:"user_id"`
+}
+
+// NullableTime distingue dans un corps JSON un champ absent (Set faux) d'un champ à null (Set vrai, Time nil)
+type NullableTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (n *NullableTime) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Time)
+}
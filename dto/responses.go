@@ -0,0 +1,100 @@
+package dto
+
+import (
+	"time"
+	"to-do-list-api/models"
+)
+
+// UserResponse représente un utilisateur dans les réponses : ni le mot de passe ni l'état du verrouillage n'y figurent
+type UserResponse struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
+	Language  string    `json:"language"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewUserResponse construit la représentation publique d'un utilisateur
+func NewUserResponse(user *models.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		IsAdmin:   user.IsAdmin,
+		Language:  user.Language,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// NewUserResponses construit la représentation publique d'une liste d'utilisateurs
+func NewUserResponses(users []models.User) []UserResponse {
+	responses := make([]UserResponse, len(users))
+	for i := range users {
+		responses[i] = NewUserResponse(&users[i])
+	}
+	return responses
+}
+
+// TaskResponse représente une tâche dans les réponses. User n'est renseigné que si la relation est développée (expand=user)
+type TaskResponse struct {
+	ID        uint          `json:"id"`
+	Title     string        `json:"title"`
+	Status    string        `json:"status"`
+	UserID    uint          `json:"user_id"`
+	Tags      []string      `json:"tags"`
+	RemindAt  *time.Time    `json:"remind_at"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	User      *UserResponse `json:"user,omitempty"`
+}
+
+// NewTaskResponse construit la représentation publique d'une tâche ; owner, s'il est fourni, est inclus dans la réponse
+func NewTaskResponse(task *models.Task, owner *models.User) TaskResponse {
+	response := TaskResponse{
+		ID:        task.ID,
+		Title:     task.Title,
+		Status:    task.Status,
+		UserID:    task.UserID,
+		Tags:      task.Tags,
+		RemindAt:  task.RemindAt,
+		CreatedAt: task.CreatedAt,
+		UpdatedAt: task.UpdatedAt,
+	}
+	if response.Tags == nil {
+		response.Tags = []string{}
+	}
+	if owner != nil {
+		user := NewUserResponse(owner)
+		response.User = &user
+	}
+	return response
+}
+
+// NewTaskResponses construit la représentation publique des tâches d'un même propriétaire
+func NewTaskResponses(tasks []models.Task, owner *models.User) []TaskResponse {
+	responses := make([]TaskResponse, len(tasks))
+	for i := range tasks {
+		responses[i] = NewTaskResponse(&tasks[i], owner)
+	}
+	return responses
+}
+
+// SessionResponse représente la session ouverte par une connexion
+type SessionResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewSessionResponse construit la représentation publique d'une session
+func NewSessionResponse(session *models.Session) SessionResponse {
+	return SessionResponse{Token: session.Token, ExpiresAt: session.ExpiresAt}
+}
+
+// LoginResponse est la réponse de POST /auth/login
+type LoginResponse struct {
+	Message string `json:"message"`
+	SessionResponse
+}
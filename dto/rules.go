@@ -0,0 +1,135 @@
+// Package dto définit les structures échangées avec les clients de l'API : le corps et les paramètres des requêtes,
+// validés de façon déclarative par des règles enregistrées une fois pour toutes auprès du validateur de gin
+package dto
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"to-do-list-api/i18n"
+	"to-do-list-api/pkg"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// TaskStatuses liste les statuts d'une tâche, dans l'ordre de leur progression
+var TaskStatuses = []string{"to-do", "in-progress", "done"}
+
+// Nombre maximal d'étiquettes par tâche
+const MaxTags = 10
+
+// Étiquette d'une tâche, après normalisation : 1 à 32 lettres minuscules, chiffres ou tirets, sans tiret initial
+var tagPattern = regexp.MustCompile(`^[\p{Ll}0-9][\p{Ll}0-9-]{0,31}$`)
+
+// Titre d'une tâche : au moins 4 lettres (accentuées ou non), chiffres ou espaces. Les sauts de ligne sont exclus :
+// le titre est repris dans l'objet de l'email de rappel
+var titlePattern = regexp.MustCompile(`^[\p{L}0-9\p{Zs}\t]{4,}$`)
+
+// Règles propres à l'API, utilisables dans les balises binding
+var rules = map[string]validator.Func{
+	"username": func(fl validator.FieldLevel) bool {
+		return pkg.ValidateUsernameFormat(fl.Field().String())
+	},
+	"emailaddr": func(fl validator.FieldLevel) bool {
+		return pkg.ValidateEmailFormat(pkg.NormalizeEmail(fl.Field().String()))
+	},
+	"title": func(fl validator.FieldLevel) bool {
+		return titlePattern.MatchString(strings.TrimSpace(fl.Field().String()))
+	},
+	"taskstatus": func(fl validator.FieldLevel) bool {
+		return IsTaskStatus(fl.Field().String())
+	},
+	"tags": func(fl validator.FieldLevel) bool {
+		tags, _ := fl.Field().Interface().([]string)
+		return ValidTags(tags)
+	},
+	"tag": func(fl validator.FieldLevel) bool {
+		return ValidTags([]string{fl.Field().String()})
+	},
+	"language": func(fl validator.FieldLevel) bool {
+		_, supported := i18n.Parse(fl.Field().String())
+		return supported
+	},
+}
+
+func init() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		panic("dto : le validateur de gin n'est pas go-playground/validator")
+	}
+	for tag, rule := range rules {
+		if err := engine.RegisterValidation(tag, rule); err != nil {
+			panic(err)
+		}
+	}
+	engine.RegisterStructValidation(passwordPolicy, RegisterRequest{}, CreateUserRequest{}, ChangePasswordRequest{})
+}
+
+// IsTaskStatus indique si status est un statut de tâche
+func IsTaskStatus(status string) bool {
+	for _, candidate := range TaskStatuses {
+		if status == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTag met une étiquette en forme : espaces retirés, minuscules
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// ValidTags indique si tags compte au plus MaxTags étiquettes valides une fois normalisées
+func ValidTags(tags []string) bool {
+	if len(tags) > MaxTags {
+		return false
+	}
+	for _, tag := range tags {
+		if !tagPattern.MatchString(NormalizeTag(tag)) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeTags normalise les étiquettes, retire les doublons et les trie. Le résultat n'est jamais nil
+func NormalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized
+}
+
+// passwordCandidate est implémentée par les requêtes portant un mot de passe soumis à la politique de robustesse
+type passwordCandidate interface {
+	// PasswordCandidate renvoie le nom JSON du champ, le mot de passe et les données de l'utilisateur qu'il ne doit pas contenir
+	PasswordCandidate() (field, password string, userInputs []string)
+}
+
+// passwordPolicy applique pkg.CheckPassword et signale chaque raison de rejet sous la règle "password", avec le code de
+// la raison en paramètre
+func passwordPolicy(sl validator.StructLevel) {
+	candidate, ok := sl.Current().Interface().(passwordCandidate)
+	if !ok {
+		return
+	}
+	field, password, userInputs := candidate.PasswordCandidate()
+	if password == "" || utf8.RuneCountInString(password) > pkg.MaxPasswordLength {
+		return // signalé par la règle required ou max
+	}
+	for _, issue := range pkg.CheckPassword(password, userInputs...) {
+		sl.ReportError(password, field, field, "password", issue.Code)
+	}
+
+	if change, ok := candidate.(ChangePasswordRequest); ok && change.NewPassword == change.CurrentPassword {
+		sl.ReportError(password, field, field, "password", "unchanged")
+	}
+}
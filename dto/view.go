@@ -0,0 +1,143 @@
+package dto
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ViewQuery regroupe les paramètres de mise en forme des réponses :
+//   - fields=id,title ne conserve que les champs nommés (sparse fieldset)
+//   - expand=user inclut la ressource liée au lieu de son seul identifiant
+type ViewQuery struct {
+	Fields string `form:"fields"`
+	Expand string `form:"expand"`
+}
+
+// View est la forme demandée d'une réponse, validée pour une ressource donnée
+type View struct {
+	fields map[string]bool // nil : tous les champs
+	expand map[string]bool
+}
+
+// UnknownMemberError signale un nom inconnu dans fields ou expand
+type UnknownMemberError struct {
+	Param   string   // paramètre en cause : "fields" ou "expand"
+	Name    string   // nom refusé
+	Allowed []string // noms acceptés pour ce paramètre
+}
+
+func (e *UnknownMemberError) Error() string {
+	return fmt.Sprintf("%s : %q inconnu (%s)", e.Param, e.Name, strings.Join(e.Allowed, ", "))
+}
+
+// NewView valide query pour resource, structure de réponse dont les champs sont désignés par leur nom JSON.
+// Seules les relations listées dans expandable peuvent être développées
+func NewView(query ViewQuery, resource any, expandable ...string) (View, error) {
+	view := View{expand: map[string]bool{}}
+
+	for _, name := range splitList(query.Expand) {
+		if !contains(expandable, name) {
+			return View{}, &UnknownMemberError{Param: "expand", Name: name, Allowed: expandable}
+		}
+		view.expand[name] = true
+	}
+
+	if names := splitList(query.Fields); len(names) > 0 {
+		members := Members(resource)
+		view.fields = make(map[string]bool, len(names))
+		for _, name := range names {
+			if !contains(members, name) {
+				return View{}, &UnknownMemberError{Param: "fields", Name: name, Allowed: members}
+			}
+			view.fields[name] = true
+		}
+	}
+	return view, nil
+}
+
+// Expands indique si la relation doit être incluse dans la réponse
+func (v View) Expands(relation string) bool {
+	return v.expand[relation]
+}
+
+// Render réduit value (structure de réponse ou tranche de structures) aux champs demandés.
+// Les relations développées sont conservées même si fields ne les nomme pas
+func (v View) Render(value any) any {
+	if v.fields == nil {
+		return value
+	}
+	rv := reflect.Indirect(reflect.ValueOf(value))
+	if rv.Kind() == reflect.Slice {
+		items := make([]map[string]any, rv.Len())
+		for i := range items {
+			items[i] = v.pick(rv.Index(i))
+		}
+		return items
+	}
+	return v.pick(rv)
+}
+
+// pick construit l'objet JSON d'une structure en ne gardant que les champs retenus
+func (v View) pick(rv reflect.Value) map[string]any {
+	rv = reflect.Indirect(rv)
+	item := make(map[string]any, len(v.fields))
+	for _, field := range reflect.VisibleFields(rv.Type()) {
+		name, omitEmpty, ok := jsonName(field)
+		if !ok || !(v.fields[name] || v.expand[name]) {
+			continue
+		}
+		value := rv.FieldByIndex(field.Index)
+		if omitEmpty && value.IsZero() {
+			continue
+		}
+		item[name] = value.Interface()
+	}
+	return item
+}
+
+// Members liste les noms JSON des champs d'une structure de réponse, dans l'ordre de déclaration
+func Members(resource any) []string {
+	var members []string
+	for _, field := range reflect.VisibleFields(reflect.Indirect(reflect.ValueOf(resource)).Type()) {
+		if name, _, ok := jsonName(field); ok {
+			members = append(members, name)
+		}
+	}
+	return members
+}
+
+// jsonName renvoie le nom JSON d'un champ exporté et l'option omitempty ; ok est faux si le champ n'est pas sérialisé
+func jsonName(field reflect.StructField) (name string, omitEmpty bool, ok bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty"), true
+}
+
+// splitList découpe une liste séparée par des virgules en ignorant les éléments vides
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func contains(list []string, name string) bool {
+	for _, candidate := range list {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+// Package i18n traduit les messages de l'API destinés aux utilisateurs. Les messages sont identifiés par une clé
+// stable et la langue est choisie par requête, d'après la préférence de l'utilisateur ou l'en-tête Accept-Language
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang est une langue prise en charge, identifiée par sa sous-étiquette principale BCP 47 ("fr", "en")
+type Lang string
+
+// Langues prises en charge
+const (
+	French  Lang = "fr"
+	English Lang = "en"
+)
+
+// Default est la langue utilisée lorsque ni l'utilisateur ni la requête n'en désignent une prise en charge,
+// ainsi que pour les messages absents du catalogue d'une langue
+var Default = French
+
+// catalogs associe à chaque langue ses messages
+var catalogs = map[Lang]map[string]string{
+	French:  french,
+	English: english,
+}
+
+// Supported renvoie les langues prises en charge, triées
+func Supported() []Lang {
+	langs := make([]Lang, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i] < langs[j] })
+	return langs
+}
+
+// Parse renvoie la langue prise en charge correspondant à l'étiquette tag ("en", "en-GB", "FR_fr"...)
+func Parse(tag string) (Lang, bool) {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	lang := Lang(primary)
+	_, supported := catalogs[lang]
+	return lang, supported
+}
+
+// Negotiate choisit, parmi les langues de l'en-tête Accept-Language, celle de plus haute préférence qui est prise en
+// charge. Sans correspondance, Default est renvoyée
+func Negotiate(acceptLanguage string) Lang {
+	best, bestWeight := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		weight := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		lang, supported := Parse(tag)
+		if supported && weight > bestWeight {
+			best, bestWeight = lang, weight
+		}
+	}
+	return best
+}
+
+type contextKey struct{}
+
+// WithLang renvoie un contexte portant la langue de la requête
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext renvoie la langue de la requête, ou Default si elle n'a pas été déterminée
+func FromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(contextKey{}).(Lang); ok {
+		return lang
+	}
+	return Default
+}
+
+// T traduit le message key dans la langue du contexte. Les arguments éventuels sont insérés à la manière de fmt.Sprintf
+func T(ctx context.Context, key string, args ...any) string {
+	return Translate(FromContext(ctx), key, args...)
+}
+
+// Translate traduit le message key dans la langue lang. Un message absent de la langue est pris dans Default,
+// et une clé inconnue est renvoyée telle quelle
+func Translate(lang Lang, key string, args ...any) string {
+	message, found := catalogs[lang][key]
+	if !found {
+		if message, found = catalogs[Default][key]; !found {
+			message = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
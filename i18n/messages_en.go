@@ -0,0 +1,159 @@
+package i18n
+
+// english regroupe les messages en anglais
+var english = map[string]string{
+	// Titres des erreurs, par code (apierror.Code)
+	"problem.invalid_request":            "Invalid request",
+	"problem.validation_failed":          "Invalid data",
+	"problem.not_found":                  "Resource not found",
+	"problem.internal_error":             "Internal error",
+	"problem.unauthenticated":            "Authentication required",
+	"problem.session_invalid":            "Invalid session",
+	"problem.session_expired":            "Session expired",
+	"problem.invalid_credentials":        "Invalid credentials",
+	"problem.current_password_incorrect": "Current password is incorrect",
+	"problem.account_disabled":           "Account disabled",
+	"problem.too_many_login_attempts":    "Too many login attempts",
+	"problem.csrf_invalid":               "Missing or invalid CSRF token",
+	"problem.admin_required":             "Administrators only",
+	"problem.rate_limited":               "Too many requests",
+	"problem.magic_link_invalid":         "Invalid or expired login link",
+	"problem.oidc_provider_unknown":      "Unknown identity provider",
+	"problem.oidc_provider_unavailable":  "Identity provider unavailable",
+	"problem.oidc_flow_invalid":          "Login flow not found or expired",
+	"problem.oidc_state_invalid":         "Invalid state parameter",
+	"problem.oidc_denied":                "Login denied by the provider",
+	"problem.oidc_authentication_failed": "Authentication with the provider failed",
+	"problem.oidc_email_missing":         "Missing or invalid provider email",
+	"problem.oidc_account_not_linked":    "No account linked to this identity",
+	"problem.oidc_email_taken":           "An account already exists with this email",
+	"problem.user_not_found":             "User not found",
+	"problem.user_forbidden":             "Account holder and administrators only",
+	"problem.username_taken":             "Username already taken",
+	"problem.email_taken":                "Email already in use",
+	"problem.account_exists":             "Email or username already in use",
+	"problem.task_not_found":             "Task not found",
+	"problem.task_forbidden":             "Action not allowed on this task",
+
+	// Détails des erreurs
+	"request.invalid_id":                 "The ID must be a valid integer",
+	"request.id_required":                "An ID is required for this operation",
+	"request.unreadable":                 "The request body could not be read",
+	"request.invalid_fields":             "Some fields are invalid",
+	"request.route_not_found":            "No resource matches %s %s",
+	"request.auth_index":                 "Register? Log in? Or log out?",
+	"request.rate_limited":               "Too many requests. Try again later.",
+	"request.csrf_invalid":               "Missing or invalid CSRF token",
+	"problem.unknown_code":               "Unknown error code: %s",
+	"validation.required":                "Required field",
+	"validation.email":                   "Invalid email format",
+	"validation.min":                     "At least %s characters",
+	"validation.max":                     "At most %s characters",
+	"validation.oneof":                   "Accepted values: %s",
+	"validation.not_expandable":          "No relation can be expanded for this resource",
+	"validation.other":                   "Invalid value (%s)",
+	"validation.type":                    "Invalid value type, %s expected",
+	"validation.username":                "Invalid username format",
+	"validation.language":                "Unsupported language",
+	"validation.title":                   "The title must contain at least 4 alphanumeric characters.",
+	"validation.status":                  "Invalid status. Options: 'to-do', 'in-progress', 'done'",
+	"validation.tags":                    "At most 10 tags of 1 to 32 letters, digits or hyphens",
+	"validation.tag":                     "A tag has 1 to 32 letters, digits or hyphens",
+	"validation.task_owner_immutable":    "The owner of a task cannot be changed",
+	"password.unchanged":                 "The new password must differ from the current one",
+	"password.current_incorrect":         "Current password is incorrect",
+	"password.too_short":                 "The password must be at least 8 characters long",
+	"password.missing_character_classes": "The password must contain an uppercase letter, a lowercase letter and a digit",
+	"password.common_password":           "This password is one of the most common",
+	"password.breached_password":         "This password appears in a list of breached passwords",
+	"password.contains_user_data":        "The password must not contain the username or the email",
+	"password.too_weak":                  "The password is too easy to guess",
+	"password.too_long":                  "The password must not exceed 72 bytes",
+	"auth.required":                      "Authentication required",
+	"auth.unauthenticated":               "User not authenticated",
+	"auth.session_invalid":               "Invalid session",
+	"auth.session_expired":               "Session expired",
+	"auth.invalid_credentials":           "Incorrect email or password",
+	"auth.account_disabled":              "This account has been disabled. Contact an administrator.",
+	"auth.too_many_attempts":             "Too many login attempts. Try again later.",
+	"auth.admin_required":                "This action is restricted to administrators",
+	"magic_link.invalid":                 "Invalid or expired login link",
+	"oidc.provider_unknown":              "Unknown identity provider",
+	"oidc.provider_unavailable":          "Identity provider unavailable",
+	"oidc.flow_invalid":                  "Login flow not found or expired",
+	"oidc.state_invalid":                 "Invalid state parameter",
+	"oidc.denied":                        "Login denied by the provider: %s",
+	"oidc.code_missing":                  "Missing authorization code",
+	"oidc.authentication_failed":         "Authentication with the provider failed",
+	"oidc.email_missing":                 "The provider did not supply a valid email",
+	"oidc.email_taken":                   "An account already exists with this email",
+	"oidc.account_not_linked":            "No account is linked to this identity",
+	"user.not_found":                     "User not found",
+	"user.username_taken":                "This username is already taken",
+	"user.email_taken":                   "This email is already in use",
+	"user.account_exists":                "This email or username is already in use",
+	"user.forbidden":                     "Only the account holder and administrators can change this account",
+	"task.not_found":                     "Task not found",
+	"task.forbidden":                     "This task belongs to another user",
+	"internal.server":                    "Internal server error",
+	"internal.current_user":              "Unable to retrieve the user",
+	"internal.login":                     "Login failed due to an internal error",
+	"internal.password_hash":             "Error while hashing the password",
+	"internal.password_check":            "Error while checking the password",
+	"internal.password_update":           "Error while updating the password",
+	"internal.session_create":            "Error while creating the session",
+	"internal.session_delete":            "Error while deleting the session",
+	"internal.user_get":                  "Error while retrieving the user",
+	"internal.user_get_for_update":       "Internal error while retrieving the user",
+	"internal.user_get_for_delete":       "Error while retrieving the user to delete",
+	"internal.user_list":                 "Error while retrieving users",
+	"internal.user_create":               "Error while creating the user",
+	"internal.account_create":            "Error while creating the user",
+	"internal.user_update":               "Internal error while saving the user's changes",
+	"internal.user_delete":               "Error while deleting the user",
+	"internal.user_unlock":               "Error while unlocking the account",
+	"internal.username_check":            "Internal error while checking the username",
+	"internal.username_unique_check":     "Internal error while checking that the username is unique",
+	"internal.email_check":               "Internal error while checking the email",
+	"internal.task_list":                 "Error while retrieving tasks",
+	"internal.task_create":               "Error while creating the task",
+	"internal.task_update":               "Error while updating the task",
+	"internal.task_delete":               "Error while deleting the task",
+	"internal.task_ownership":            "Internal error while checking the task's owner",
+	"internal.task_from_context":         "Unable to retrieve the task from the context",
+	"internal.task_type":                 "Unable to convert the task retrieved from the context",
+	"internal.magic_link_request":        "Internal error while requesting the link",
+	"internal.magic_link_create":         "Error while creating the login link",
+	"internal.magic_link_send":           "Error while sending the login link",
+	"internal.magic_link_check":          "Internal error while checking the link",
+	"internal.email_change":              "Error while changing the email address",
+	"internal.oidc_prepare":              "Error while preparing the login",
+	"internal.identity_lookup":           "Internal error while looking up the external identity",
+	"internal.identity_link":             "Error while linking the external identity",
+	"internal.account_lookup":            "Internal error while looking up the account",
+
+	// Messages de succès et contenus
+	"auth.registered":                "Registration successful",
+	"auth.logged_in":                 "Login successful",
+	"auth.logged_out":                "Logout successful",
+	"auth.password_changed":          "Password changed successfully",
+	"auth.language_changed":          "Language changed successfully",
+	"magic_link.sent":                "If an account matches this email, a login link has just been sent",
+	"magic_link.mail_subject":        "Your login link",
+	"magic_link.mail_body":           "Hello %s,\n\nClick the following link to log in. It is valid for %s and can only be used once:\n\n%s\n\nIf you did not request it, ignore this email.",
+	"magic_link.page_title":          "Log in",
+	"magic_link.page_button":         "Log in",
+	"user.email_change_pending":      "User %s updated; the new address %s will be saved as soon as the link just sent to it has been used",
+	"user.email_change_mail_subject": "Confirm your new email address",
+	"user.email_change_mail_body":    "Hello %s,\n\nClick the following link to confirm your new email address and log in. It is valid for %s and can only be used once:\n\n%s\n\nIf you did not request it, ignore this email: the address of your account will not change.",
+	"user.email_changed":             "Email address confirmed, login successful",
+	"user.created":                   "User %s created successfully",
+	"user.updated":                   "User %s updated successfully",
+	"user.deleted":                   "User %s deleted successfully",
+	"user.unlocked":                  "Account of %s unlocked successfully",
+	"task.created":                   "Task %s created and assigned to user %s successfully",
+	"task.updated":                   "Task updated successfully",
+	"task.deleted":                   "Task deleted successfully",
+	"reminder.mail_subject":          "Reminder: %s",
+	"reminder.mail_body":             "Hello %s,\n\nYou asked to be reminded of the task \"%s\" (status: %s).\n\nTo stop receiving this reminder, change or remove the reminder date of the task.",
+}
@@ -0,0 +1,159 @@
+package i18n
+
+// french regroupe les messages en français, langue par défaut de l'API
+var french = map[string]string{
+	// Titres des erreurs, par code (apierror.Code)
+	"problem.invalid_request":            "Requête invalide",
+	"problem.validation_failed":          "Données invalides",
+	"problem.not_found":                  "Ressource introuvable",
+	"problem.internal_error":             "Erreur interne",
+	"problem.unauthenticated":            "Authentification requise",
+	"problem.session_invalid":            "Session invalide",
+	"problem.session_expired":            "Session expirée",
+	"problem.invalid_credentials":        "Identifiants incorrects",
+	"problem.current_password_incorrect": "Mot de passe actuel incorrect",
+	"problem.account_disabled":           "Compte désactivé",
+	"problem.too_many_login_attempts":    "Trop de tentatives de connexion",
+	"problem.csrf_invalid":               "Jeton CSRF manquant ou invalide",
+	"problem.admin_required":             "Action réservée aux administrateurs",
+	"problem.rate_limited":               "Trop de requêtes",
+	"problem.magic_link_invalid":         "Lien de connexion invalide ou expiré",
+	"problem.oidc_provider_unknown":      "Fournisseur d'identité inconnu",
+	"problem.oidc_provider_unavailable":  "Fournisseur d'identité indisponible",
+	"problem.oidc_flow_invalid":          "Flux de connexion introuvable ou expiré",
+	"problem.oidc_state_invalid":         "Paramètre state invalide",
+	"problem.oidc_denied":                "Connexion refusée par le fournisseur",
+	"problem.oidc_authentication_failed": "Échec de l'authentification auprès du fournisseur",
+	"problem.oidc_email_missing":         "Email du fournisseur absent ou invalide",
+	"problem.oidc_account_not_linked":    "Aucun compte associé à cette identité",
+	"problem.oidc_email_taken":           "Un compte existe déjà avec cet email",
+	"problem.user_not_found":             "Utilisateur introuvable",
+	"problem.user_forbidden":             "Action réservée au titulaire du compte et aux administrateurs",
+	"problem.username_taken":             "Username déjà utilisé",
+	"problem.email_taken":                "Email déjà utilisé",
+	"problem.account_exists":             "Email ou username déjà utilisé",
+	"problem.task_not_found":             "Tâche introuvable",
+	"problem.task_forbidden":             "Action non autorisée sur cette tâche",
+
+	// Détails des erreurs
+	"request.invalid_id":                 "L'ID doit être un entier valide",
+	"request.id_required":                "L'ID est requis pour cette opération",
+	"request.unreadable":                 "Le corps de la requête est illisible",
+	"request.invalid_fields":             "Certains champs sont invalides",
+	"request.route_not_found":            "Aucune ressource ne correspond à %s %s",
+	"request.auth_index":                 "Inscription ? Connexion ? Ou déconnexion ?",
+	"request.rate_limited":               "Trop de requêtes. Réessayez plus tard.",
+	"request.csrf_invalid":               "Jeton CSRF manquant ou invalide",
+	"problem.unknown_code":               "Code d'erreur inconnu : %s",
+	"validation.required":                "Champ requis",
+	"validation.email":                   "Format d'email invalide",
+	"validation.min":                     "Au moins %s caractères",
+	"validation.max":                     "Au plus %s caractères",
+	"validation.oneof":                   "Valeurs acceptées : %s",
+	"validation.not_expandable":          "Aucune relation ne peut être développée pour cette ressource",
+	"validation.other":                   "Valeur invalide (%s)",
+	"validation.type":                    "Type de valeur invalide, %s attendu",
+	"validation.username":                "Format du username invalide",
+	"validation.language":                "Langue non prise en charge",
+	"validation.title":                   "Le titre doit comporter au moins 4 caractères alphanumériques.",
+	"validation.status":                  "Statut invalide. Options : 'to-do', 'in-progress', 'done'",
+	"validation.tags":                    "Au plus 10 étiquettes de 1 à 32 lettres, chiffres ou tirets",
+	"validation.tag":                     "Une étiquette comporte 1 à 32 lettres, chiffres ou tirets",
+	"validation.task_owner_immutable":    "Le propriétaire d'une tâche ne peut pas être modifié",
+	"password.unchanged":                 "Le nouveau mot de passe doit être différent de l'actuel",
+	"password.current_incorrect":         "Mot de passe actuel incorrect",
+	"password.too_short":                 "Le mot de passe doit contenir au moins 8 caractères",
+	"password.missing_character_classes": "Le mot de passe doit contenir une majuscule, une minuscule et un chiffre",
+	"password.common_password":           "Ce mot de passe fait partie des plus courants",
+	"password.breached_password":         "Ce mot de passe figure dans une liste de mots de passe compromis",
+	"password.contains_user_data":        "Le mot de passe ne doit pas contenir le username ou l'email",
+	"password.too_weak":                  "Le mot de passe est trop facile à deviner",
+	"password.too_long":                  "Le mot de passe ne doit pas dépasser 72 octets",
+	"auth.required":                      "Authentification requise",
+	"auth.unauthenticated":               "Utilisateur non authentifié",
+	"auth.session_invalid":               "Session invalide",
+	"auth.session_expired":               "Session expirée",
+	"auth.invalid_credentials":           "Email ou mot de passe incorrect",
+	"auth.account_disabled":              "Ce compte a été désactivé. Contactez un administrateur.",
+	"auth.too_many_attempts":             "Trop de tentatives de connexion. Réessayez plus tard.",
+	"auth.admin_required":                "Action réservée aux administrateurs",
+	"magic_link.invalid":                 "Lien de connexion invalide ou expiré",
+	"oidc.provider_unknown":              "Fournisseur d'identité inconnu",
+	"oidc.provider_unavailable":          "Fournisseur d'identité indisponible",
+	"oidc.flow_invalid":                  "Flux de connexion introuvable ou expiré",
+	"oidc.state_invalid":                 "Paramètre state invalide",
+	"oidc.denied":                        "Connexion refusée par le fournisseur : %s",
+	"oidc.code_missing":                  "Code d'autorisation manquant",
+	"oidc.authentication_failed":         "Échec de l'authentification auprès du fournisseur",
+	"oidc.email_missing":                 "Le fournisseur n'a pas transmis d'email valide",
+	"oidc.email_taken":                   "Un compte existe déjà avec cet email",
+	"oidc.account_not_linked":            "Aucun compte n'est associé à cette identité",
+	"user.not_found":                     "Utilisateur introuvable",
+	"user.username_taken":                "Ce username est déjà utilisé",
+	"user.email_taken":                   "Cet email est déjà utilisé",
+	"user.account_exists":                "Cet email ou username est déjà utilisé",
+	"user.forbidden":                     "Seuls le titulaire du compte et les administrateurs peuvent le modifier",
+	"task.not_found":                     "Tâche non trouvée",
+	"task.forbidden":                     "Cette tâche appartient à un autre utilisateur",
+	"internal.server":                    "Erreur interne du serveur",
+	"internal.current_user":              "Impossible de récupérer l'utilisateur",
+	"internal.login":                     "Echec de la connexion dûe à une erreur interne",
+	"internal.password_hash":             "Erreur lors du hachage du mot de passe",
+	"internal.password_check":            "Erreur lors de la vérification du mot de passe",
+	"internal.password_update":           "Erreur lors de la mise à jour du mot de passe",
+	"internal.session_create":            "Erreur lors de la création de la session",
+	"internal.session_delete":            "Erreur lors de la suppression de la session",
+	"internal.user_get":                  "Erreur lors de la récupération de l'utilisateur",
+	"internal.user_get_for_update":       "Erreur interne lors de la récupération du user",
+	"internal.user_get_for_delete":       "Erreur lors de la récupération de l'utilisateur à supprimer",
+	"internal.user_list":                 "Erreur lors de la récupération des users",
+	"internal.user_create":               "Erreur lors de la création du user",
+	"internal.account_create":            "Erreur lors de la création de l'utilisateur",
+	"internal.user_update":               "Erreur interne lors de la sauvegarde des mises à jour de l'utilisateur",
+	"internal.user_delete":               "Erreur lors de la suppression de l'utilisateur",
+	"internal.user_unlock":               "Erreur lors du déverrouillage du compte",
+	"internal.username_check":            "Erreur interne lors de la vérification du username",
+	"internal.username_unique_check":     "Erreur interne lors de la vérification de l'unicité du username",
+	"internal.email_check":               "Erreur interne lors de la vérification de l'email",
+	"internal.task_list":                 "Erreur lors de la récupération des tâches",
+	"internal.task_create":               "Erreur lors de la création de la tâche",
+	"internal.task_update":               "Erreur lors de la mise à jour de la tâche",
+	"internal.task_delete":               "Erreur lors de la suppression de la tâche",
+	"internal.task_ownership":            "Erreur interne lors de la vérification de l'appartenance de la tâche à l'utilisateur",
+	"internal.task_from_context":         "Impossible de récupérer la tâche depuis le contexte",
+	"internal.task_type":                 "Impossible de convertir le type de la tâche récupérée depuis le contexte",
+	"internal.magic_link_request":        "Erreur interne lors de la demande de lien",
+	"internal.magic_link_create":         "Erreur lors de la création du lien de connexion",
+	"internal.magic_link_send":           "Erreur lors de l'envoi du lien de connexion",
+	"internal.magic_link_check":          "Erreur interne lors de la vérification du lien",
+	"internal.email_change":              "Erreur lors du changement d'adresse email",
+	"internal.oidc_prepare":              "Erreur lors de la préparation de la connexion",
+	"internal.identity_lookup":           "Erreur interne lors de la recherche de l'identité externe",
+	"internal.identity_link":             "Erreur lors de la liaison de l'identité externe",
+	"internal.account_lookup":            "Erreur interne lors de la recherche du compte",
+
+	// Messages de succès et contenus
+	"auth.registered":                "Inscription réussie",
+	"auth.logged_in":                 "Connexion réussie",
+	"auth.logged_out":                "Déconnexion réussie",
+	"auth.password_changed":          "Mot de passe modifié avec succès",
+	"auth.language_changed":          "Langue modifiée avec succès",
+	"magic_link.sent":                "Si un compte correspond à cet email, un lien de connexion vient d'être envoyé",
+	"magic_link.mail_subject":        "Votre lien de connexion",
+	"magic_link.mail_body":           "Bonjour %s,\n\nCliquez sur le lien suivant pour vous connecter. Il est valable %s et ne peut servir qu'une fois :\n\n%s\n\nSi vous n'êtes pas à l'origine de cette demande, ignorez cet email.",
+	"magic_link.page_title":          "Connexion",
+	"magic_link.page_button":         "Se connecter",
+	"user.email_change_pending":      "User %s mis à jour ; la nouvelle adresse %s sera enregistrée dès que le lien qui vient de lui être envoyé aura été utilisé",
+	"user.email_change_mail_subject": "Confirmez votre nouvelle adresse email",
+	"user.email_change_mail_body":    "Bonjour %s,\n\nCliquez sur le lien suivant pour confirmer votre nouvelle adresse email et vous connecter. Il est valable %s et ne peut servir qu'une fois :\n\n%s\n\nSi vous n'êtes pas à l'origine de cette demande, ignorez cet email : l'adresse de votre compte ne sera pas modifiée.",
+	"user.email_changed":             "Adresse email confirmée, connexion réussie",
+	"user.created":                   "User %s créé avec succès",
+	"user.updated":                   "User %s mis à jour avec succès",
+	"user.deleted":                   "Utilisateur %s supprimé avec succès",
+	"user.unlocked":                  "Compte de %s déverrouillé avec succès",
+	"task.created":                   "Tâche %s créée et associée au user %s avec succès",
+	"task.updated":                   "Tâche mis à jour avec succès",
+	"task.deleted":                   "Tâche supprimée avec succès",
+	"reminder.mail_subject":          "Rappel : %s",
+	"reminder.mail_body":             "Bonjour %s,\n\nVous avez demandé à être rappelé de la tâche « %s » (statut : %s).\n\nPour ne plus recevoir ce rappel, modifiez ou supprimez la date de rappel de la tâche.",
+}
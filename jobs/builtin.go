@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"to-do-list-api/i18n"
+	"to-do-list-api/models"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
+)
+
+// Noms des tâches intégrées
+const (
+	SessionCleanup = "session-cleanup"
+	TrashPurge     = "trash-purge"
+	Reminders      = "reminders"
+)
+
+// Nombre maximal de rappels envoyés par exécution ; les suivants partent à l'exécution suivante
+const reminderBatch = 100
+
+// Nombre d'envois en échec après lequel un rappel est abandonné
+const reminderMaxAttempts = 5
+
+// Options règle le planificateur et ses tâches intégrées. Une planification vide désactive la tâche correspondante
+type Options struct {
+	Timeout        time.Duration
+	HistorySize    int
+	SessionCleanup string
+	TrashPurge     string
+	TrashRetention time.Duration
+	Reminders      string
+}
+
+// New crée un planificateur portant les tâches intégrées dont la planification est renseignée
+func New(s *store.Store, opts Options) (*Scheduler, error) {
+	scheduler := NewScheduler(s.Jobs, opts.Timeout, opts.HistorySize)
+	builtins := []struct {
+		name string
+		spec string
+		run  func(ctx context.Context) (int64, error)
+	}{
+		{SessionCleanup, opts.SessionCleanup, cleanSessions(s)},
+		{TrashPurge, opts.TrashPurge, purgeTrash(s, opts.TrashRetention)},
+		{Reminders, opts.Reminders, sendReminders(s)},
+	}
+	for _, builtin := range builtins {
+		if builtin.spec == "" {
+			continue
+		}
+		schedule, err := ParseSchedule(builtin.spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s : %w", builtin.name, err)
+		}
+		scheduler.Add(Job{Name: builtin.name, Schedule: schedule, Run: builtin.run})
+	}
+	return scheduler, nil
+}
+
+// cleanSessions supprime les sessions expirées
+func cleanSessions(s *store.Store) func(ctx context.Context) (int64, error) {
+	return func(ctx context.Context) (int64, error) {
+		return s.Sessions.DeleteExpiredSessions(ctx, time.Now())
+	}
+}
+
+// purgeTrash supprime définitivement les tâches mises à la corbeille depuis plus de retention
+func purgeTrash(s *store.Store, retention time.Duration) func(ctx context.Context) (int64, error) {
+	return func(ctx context.Context) (int64, error) {
+		return s.Tasks.PurgeDeletedTasks(ctx, time.Now().Add(-retention))
+	}
+}
+
+// sendReminders envoie par email les rappels échus, dans la langue de chaque utilisateur. Un envoi en échec est
+// retenté aux exécutions suivantes, après les autres rappels, puis abandonné au bout de reminderMaxAttempts échecs ;
+// les comptes désactivés ne reçoivent rien
+func sendReminders(s *store.Store) func(ctx context.Context) (int64, error) {
+	return func(ctx context.Context) (int64, error) {
+		now := time.Now()
+		tasks, err := s.Tasks.ListDueReminders(ctx, now, reminderMaxAttempts, reminderBatch)
+		if err != nil {
+			return 0, err
+		}
+
+		var sent int64
+		var errs []error
+		for i := range tasks {
+			task := &tasks[i]
+			if !task.User.IsDisabled() {
+				if err := pkg.DefaultMailer.Send(ctx, reminderMail(task)); err != nil {
+					errs = append(errs, fmt.Errorf("tâche %d : %w", task.ID, err))
+					if err := s.Tasks.RecordReminderFailure(ctx, task.ID); err != nil {
+						slog.ErrorContext(ctx, "enregistrement de l'échec du rappel impossible", "task_id", task.ID, "error", err)
+					} else if task.ReminderAttempts+1 >= reminderMaxAttempts {
+						slog.WarnContext(ctx, "rappel abandonné après plusieurs échecs", "task_id", task.ID, "attempts", reminderMaxAttempts)
+					}
+					continue
+				}
+				sent++
+			}
+			if err := s.Tasks.MarkReminded(ctx, task.ID, now); err != nil {
+				//Le rappel est parti : il repartira à l'exécution suivante, faute d'avoir été enregistré
+				slog.ErrorContext(ctx, "enregistrement de l'envoi du rappel impossible", "task_id", task.ID, "error", err)
+				errs = append(errs, fmt.Errorf("tâche %d : %w", task.ID, err))
+			}
+		}
+		return sent, errors.Join(errs...)
+	}
+}
+
+// reminderMail compose l'email de rappel d'une tâche, dont l'utilisateur est chargé
+func reminderMail(task *models.Task) pkg.Mail {
+	lang := i18n.Default
+	if preferred, supported := i18n.Parse(task.User.Language); supported {
+		lang = preferred
+	}
+	//Un titre enregistré avant l'exclusion des sauts de ligne ne doit pas casser l'en-tête Subject
+	title := strings.Join(strings.Fields(task.Title), " ")
+	return pkg.Mail{
+		To:      task.User.Email,
+		Subject: i18n.Translate(lang, "reminder.mail_subject", title),
+		Body:    i18n.Translate(lang, "reminder.mail_body", task.User.Username, task.Title, task.Status),
+	}
+}
@@ -0,0 +1,185 @@
+package jobs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule calcule les dates d'exécution d'une tâche de fond
+type Schedule interface {
+	// Next renvoie la première date d'exécution strictement postérieure à t, ou la date nulle s'il n'y en a aucune
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// Raccourcis acceptés par ParseSchedule, équivalents d'une expression cron
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule lit une planification : expression cron à 5 champs (minute, heure, jour du mois, mois, jour de la
+// semaine, avec *, listes, intervalles et pas), raccourci (@hourly, @daily...) ou intervalle fixe (@every 10m)
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if every, found := strings.CutPrefix(spec, "@every "); found {
+		interval, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("planification %q : durée d'au moins 1s attendue après @every", spec)
+		}
+		return everySchedule{interval}, nil
+	}
+
+	expression := spec
+	if strings.HasPrefix(spec, "@") {
+		var found bool
+		if expression, found = descriptors[spec]; !found {
+			return nil, fmt.Errorf("planification %q : raccourci inconnu", spec)
+		}
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("planification %q : 5 champs attendus (minute heure jour mois jour-de-semaine)", spec)
+	}
+
+	cron := cronSchedule{spec: spec}
+	bounds := []struct {
+		name     string
+		min, max int
+		bits     *uint64
+	}{
+		{"minute", 0, 59, &cron.minute},
+		{"heure", 0, 23, &cron.hour},
+		{"jour du mois", 1, 31, &cron.dom},
+		{"mois", 1, 12, &cron.month},
+		{"jour de la semaine", 0, 7, &cron.dow},
+	}
+	for i, b := range bounds {
+		bits, err := parseField(fields[i], b.min, b.max)
+		if err != nil {
+			return nil, fmt.Errorf("planification %q, %s : %w", spec, b.name, err)
+		}
+		*b.bits = bits
+	}
+	//7 désigne aussi le dimanche
+	if cron.dow&(1<<7) != 0 {
+		cron.dow = cron.dow&^(1<<7) | 1
+	}
+	cron.domAny, cron.dowAny = strings.HasPrefix(fields[2], "*"), strings.HasPrefix(fields[4], "*")
+	if cron.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("planification %q : aucune date ne correspond", spec)
+	}
+	return cron, nil
+}
+
+// parseField convertit un champ cron en ensemble de valeurs (bit i pour la valeur i)
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return 0, fmt.Errorf("pas invalide %q", stepPart)
+			}
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err1, err2 error
+			low, err1 = strconv.Atoi(from)
+			high, err2 = strconv.Atoi(to)
+			if err1 != nil || err2 != nil {
+				return 0, fmt.Errorf("intervalle invalide %q", rangePart)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("valeur invalide %q", rangePart)
+			}
+			low = value
+			if !hasStep {
+				high = value
+			}
+		}
+		if low < min || high > max || low > high {
+			return 0, fmt.Errorf("%q hors de l'intervalle %d-%d", part, min, max)
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// Ensemble des heures d'une journée : un champ heure égal à allHours n'est pas restreint
+const allHours = 1<<24 - 1
+
+// cronSchedule est une expression cron, évaluée dans le fuseau de la date passée à Next
+type cronSchedule struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+func (c cronSchedule) String() string { return c.spec }
+
+func (c cronSchedule) Next(from time.Time) time.Time {
+	t := from.Truncate(time.Minute).Add(time.Minute)
+	//Une expression valide trouve une date en moins de 5 ans (29 février compris) ; au-delà, elle n'en trouvera jamais
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		case c.hour != allHours && t.Add(-time.Hour).Hour() == t.Hour() && !t.Add(-time.Hour).After(from):
+			//Heure répétée au passage à l'heure d'hiver, déjà écoulée à la date from : une planification à heure fixe
+			//ne s'exécute qu'une fois
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applique la règle de cron : si le jour du mois et le jour de la semaine sont tous deux restreints, il
+// suffit que l'un des deux corresponde
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny || c.dowAny:
+		return dom && dow
+	default:
+		return dom || dow
+	}
+}
+
+// everySchedule exécute la tâche à intervalle fixe
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (e everySchedule) String() string { return "@every " + e.interval.String() }
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(e.interval)
+}
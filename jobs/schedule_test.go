@@ -0,0 +1,223 @@
+package jobs
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata" // fuseaux horaires disponibles quelle que soit la machine de test
+)
+
+// at construit une date dans le fuseau loc
+func at(loc *time.Location, layout string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", layout, loc)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseScheduleNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from string
+		want []string // dates d'exécution successives, au format 2006-01-02 15:04 (UTC)
+	}{
+		{"chaque minute", "* * * * *", "2026-01-01 10:00", []string{"2026-01-01 10:01", "2026-01-01 10:02"}},
+		{"minute fixe", "30 * * * *", "2026-01-01 10:30", []string{"2026-01-01 11:30", "2026-01-01 12:30"}},
+		{"intervalle", "10-12 * * * *", "2026-01-01 10:11", []string{"2026-01-01 10:12", "2026-01-01 11:10"}},
+		{"pas", "*/20 * * * *", "2026-01-01 10:05", []string{"2026-01-01 10:20", "2026-01-01 10:40", "2026-01-01 11:00"}},
+		{"pas depuis une valeur", "5/20 * * * *", "2026-01-01 10:00", []string{"2026-01-01 10:05", "2026-01-01 10:25", "2026-01-01 10:45"}},
+		{"intervalle avec pas", "0 8-18/5 * * *", "2026-01-01 00:00", []string{"2026-01-01 08:00", "2026-01-01 13:00", "2026-01-01 18:00", "2026-01-02 08:00"}},
+		{"liste", "0 9,12,18 * * *", "2026-01-01 12:00", []string{"2026-01-01 18:00", "2026-01-02 09:00"}},
+		{"fin de mois", "0 0 31 * *", "2026-01-31 00:00", []string{"2026-03-31 00:00", "2026-05-31 00:00"}},
+		{"29 février", "0 0 29 2 *", "2026-01-01 00:00", []string{"2028-02-29 00:00", "2032-02-29 00:00"}},
+		{"jour de semaine", "0 0 * * 1", "2026-01-01 00:00", []string{"2026-01-05 00:00", "2026-01-12 00:00"}},
+		{"7 désigne le dimanche", "0 0 * * 7", "2026-01-01 00:00", []string{"2026-01-04 00:00", "2026-01-11 00:00"}},
+		{"intervalle jusqu'au dimanche", "0 0 * * 6-7", "2026-01-01 00:00", []string{"2026-01-03 00:00", "2026-01-04 00:00", "2026-01-10 00:00"}},
+		// Jour du mois et jour de la semaine restreints : l'un ou l'autre suffit (le 1er, et chaque lundi)
+		{"jour du mois ou de la semaine", "0 0 1 * 1", "2026-01-01 00:00", []string{"2026-01-05 00:00", "2026-01-12 00:00", "2026-01-19 00:00", "2026-01-26 00:00", "2026-02-01 00:00", "2026-02-02 00:00"}},
+		// Un seul des deux restreint : il doit correspondre
+		{"jour du mois seul", "0 0 13 * *", "2026-01-01 00:00", []string{"2026-01-13 00:00", "2026-02-13 00:00"}},
+		{"jour de la semaine seul", "0 0 * * 5", "2026-02-12 00:00", []string{"2026-02-13 00:00", "2026-02-20 00:00"}},
+		// Un champ commençant par * (pas compris) n'est pas restreint au sens de cette règle : les deux doivent correspondre
+		{"jour du mois avec pas", "0 0 */10 * 1", "2026-01-01 00:00", []string{"2026-05-11 00:00", "2026-06-01 00:00"}},
+		{"@yearly", "@yearly", "2026-06-01 00:00", []string{"2027-01-01 00:00", "2028-01-01 00:00"}},
+		{"@annually", "@annually", "2026-06-01 00:00", []string{"2027-01-01 00:00"}},
+		{"@monthly", "@monthly", "2026-01-15 00:00", []string{"2026-02-01 00:00", "2026-03-01 00:00"}},
+		{"@weekly", "@weekly", "2026-01-01 00:00", []string{"2026-01-04 00:00", "2026-01-11 00:00"}},
+		{"@daily", "@daily", "2026-01-01 00:00", []string{"2026-01-02 00:00"}},
+		{"@midnight", "@midnight", "2026-01-01 23:59", []string{"2026-01-02 00:00"}},
+		{"@hourly", "@hourly", "2026-01-01 10:15", []string{"2026-01-01 11:00", "2026-01-01 12:00"}},
+		{"@every", "@every 90m", "2026-01-01 10:15", []string{"2026-01-01 11:45", "2026-01-01 13:15"}},
+		{"espaces superflus", "  0   12 * * *  ", "2026-01-01 00:00", []string{"2026-01-01 12:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q) : %v", tt.spec, err)
+			}
+			next := at(time.UTC, tt.from)
+			for _, want := range tt.want {
+				next = schedule.Next(next)
+				if got := next.Format("2006-01-02 15:04"); got != want {
+					t.Fatalf("Next : %s, %s attendu", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseScheduleNextSkipsSeconds(t *testing.T) {
+	schedule, err := ParseSchedule("* * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 1, 1, 10, 0, 59, 999, time.UTC)
+	if got, want := schedule.Next(from), time.Date(2026, 1, 1, 10, 1, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next(%v) = %v, %v attendu", from, got, want)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string // extrait du message d'erreur
+	}{
+		{"", "5 champs attendus"},
+		{"* * * *", "5 champs attendus"},
+		{"* * * * * *", "5 champs attendus"},
+		{"60 * * * *", "hors de l'intervalle"},
+		{"* 24 * * *", "hors de l'intervalle"},
+		{"* * 0 * *", "hors de l'intervalle"},
+		{"* * 32 * *", "hors de l'intervalle"},
+		{"* * * 13 *", "hors de l'intervalle"},
+		{"* * * * 8", "hors de l'intervalle"},
+		{"10-5 * * * *", "hors de l'intervalle"},
+		{"*/0 * * * *", "pas invalide"},
+		{"*/x * * * *", "pas invalide"},
+		{"a-b * * * *", "intervalle invalide"},
+		{"x * * * *", "valeur invalide"},
+		{"1,,2 * * * *", "valeur invalide"},
+		{"@sometimes", "raccourci inconnu"},
+		{"@every", "raccourci inconnu"},
+		{"@every 10ms", "durée d'au moins 1s"},
+		{"@every demain", "durée d'au moins 1s"},
+		// Dates impossibles : l'analyse s'arrête sur une erreur au lieu de chercher indéfiniment
+		{"0 0 31 2 *", "aucune date ne correspond"},
+		{"0 0 30 2 *", "aucune date ne correspond"},
+		{"0 0 31 4,6,9,11 *", "aucune date ne correspond"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			done := make(chan error, 1)
+			go func() {
+				_, err := ParseSchedule(tt.spec)
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Errorf("ParseSchedule(%q) : erreur %v, %q attendu", tt.spec, err, tt.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("ParseSchedule(%q) ne se termine pas", tt.spec)
+			}
+		})
+	}
+}
+
+// Un jour du mois impossible est accepté si le jour de la semaine offre d'autres dates (règle du ou)
+func TestParseScheduleImpossibleDayWithWeekday(t *testing.T) {
+	schedule, err := ParseSchedule("0 0 31 2 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := schedule.Next(at(time.UTC, "2026-01-01 00:00")).Format("2006-01-02"); got != "2026-02-02" {
+		t.Errorf("Next : %s, 2026-02-02 (premier lundi de février) attendu", got)
+	}
+}
+
+// Les changements d'heure de Paris (29 mars et 25 octobre 2026, à 2h et 3h)
+func TestParseScheduleDST(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			// 2h30 n'existe pas le 29 mars : l'exécution du jour est sautée
+			name: "passage à l'heure d'été, heure inexistante",
+			spec: "30 2 * * *",
+			from: at(paris, "2026-03-28 12:00"),
+			want: []time.Time{at(paris, "2026-03-30 02:30"), at(paris, "2026-03-31 02:30")},
+		},
+		{
+			name: "passage à l'heure d'été, heure suivante",
+			spec: "0 3 * * *",
+			from: at(paris, "2026-03-28 12:00"),
+			want: []time.Time{at(paris, "2026-03-29 03:00"), at(paris, "2026-03-30 03:00")},
+		},
+		{
+			// 1h59 CET est suivi de 3h00 CEST, une minute plus tard
+			name: "passage à l'heure d'été, chaque minute",
+			spec: "* * * * *",
+			from: at(paris, "2026-03-29 01:59"),
+			want: []time.Time{at(paris, "2026-03-29 01:59").Add(time.Minute)},
+		},
+		{
+			// 2h30 a lieu deux fois le 25 octobre : une seule exécution
+			name: "passage à l'heure d'hiver, heure répétée",
+			spec: "30 2 * * *",
+			from: at(paris, "2026-10-24 12:00"),
+			want: []time.Time{at(paris, "2026-10-25 02:30"), at(paris, "2026-10-26 02:30")},
+		},
+		{
+			// Première exécution à 2h30 CEST : 2h30 CET, une heure plus tard, est la même heure et n'est pas reprise
+			name: "passage à l'heure d'hiver, exécution pendant la première occurrence",
+			spec: "30 2 * * *",
+			from: time.Date(2026, 10, 25, 0, 10, 0, 0, time.UTC).In(paris), // 2h10 CEST
+			want: []time.Time{time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC), at(paris, "2026-10-26 02:30")},
+		},
+		{
+			// Les planifications sans heure fixe suivent le temps écoulé, y compris pendant l'heure répétée
+			name: "passage à l'heure d'hiver, toutes les 30 minutes",
+			spec: "*/30 * * * *",
+			from: time.Date(2026, 10, 24, 23, 45, 0, 0, time.UTC).In(paris), // 1h45 CEST
+			want: []time.Time{
+				time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC),  // 2h00 CEST
+				time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC), // 2h30 CEST
+				time.Date(2026, 10, 25, 1, 0, 0, 0, time.UTC),  // 2h00 CET
+				time.Date(2026, 10, 25, 1, 30, 0, 0, time.UTC), // 2h30 CET
+				time.Date(2026, 10, 25, 2, 0, 0, 0, time.UTC),  // 3h00 CET
+			},
+		},
+		{
+			name: "@every suit le temps écoulé",
+			spec: "@every 1h",
+			from: time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC).In(paris),
+			want: []time.Time{time.Date(2026, 10, 25, 1, 0, 0, 0, time.UTC), time.Date(2026, 10, 25, 2, 0, 0, 0, time.UTC)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := tt.from
+			for _, want := range tt.want {
+				next = schedule.Next(next)
+				if !next.Equal(want) {
+					t.Fatalf("Next : %v, %v attendu", next, want.In(paris))
+				}
+			}
+		})
+	}
+}
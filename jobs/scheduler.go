@@ -0,0 +1,294 @@
+// Package jobs exécute dans le processus du serveur les tâches de fond planifiées (nettoyage des sessions, purge de la
+// corbeille, rappels). Chaque exécution est réservée à une seule instance par un verrou en base, inscrite dans
+// l'historique et mesurée par les métriques Prometheus
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"to-do-list-api/metrics"
+	"to-do-list-api/models"
+	"to-do-list-api/store"
+	"unicode/utf8"
+)
+
+// Marge ajoutée à la durée maximale d'une exécution pour l'expiration de son verrou
+const lockMargin = time.Minute
+
+// Origines d'une exécution
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	// ErrUnknownJob est renvoyée par RunNow pour une tâche qui n'est pas enregistrée
+	ErrUnknownJob = errors.New("tâche de fond inconnue")
+	// ErrJobBusy est renvoyée par RunNow lorsque la tâche s'exécute déjà, dans ce processus ou dans une autre instance,
+	// ou qu'une autre instance vient de l'exécuter à son échéance
+	ErrJobBusy = errors.New("tâche de fond déjà en cours d'exécution")
+)
+
+// Job est une tâche de fond
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Run exécute la tâche et renvoie le nombre d'éléments traités
+	Run func(ctx context.Context) (int64, error)
+}
+
+// entry est une tâche enregistrée auprès du planificateur
+type entry struct {
+	job     Job
+	next    time.Time
+	running atomic.Bool // une seule exécution à la fois dans le processus
+}
+
+// Scheduler déclenche les tâches enregistrées selon leur planification
+type Scheduler struct {
+	store       store.JobStore
+	instance    string
+	timeout     time.Duration
+	historySize int
+
+	mu         sync.Mutex
+	entries    []*entry
+	stopLoop   context.CancelFunc // arrête la planification
+	cancelRuns context.CancelFunc // interrompt les exécutions en cours
+	done       chan struct{}      // fermé à la fin de la boucle de planification
+	runs       sync.WaitGroup
+}
+
+// NewScheduler crée un planificateur sans tâche. timeout borne chaque exécution ; historySize exécutions sont
+// conservées par tâche
+func NewScheduler(js store.JobStore, timeout time.Duration, historySize int) *Scheduler {
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		store:       js,
+		instance:    fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		timeout:     timeout,
+		historySize: historySize,
+	}
+}
+
+// Add enregistre une tâche ; à appeler avant Start
+func (s *Scheduler) Add(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{job: job})
+}
+
+// Jobs renvoie les tâches enregistrées, par nom
+func (s *Scheduler) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, len(s.entries))
+	for i, e := range s.entries {
+		jobs[i] = e.job
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+// Start lance la boucle de planification en arrière-plan
+func (s *Scheduler) Start() {
+	loopCtx, stopLoop := context.WithCancel(context.Background())
+	runCtx, cancelRuns := context.WithCancel(context.Background())
+	s.stopLoop, s.cancelRuns = stopLoop, cancelRuns
+	s.done = make(chan struct{})
+	go s.loop(loopCtx, runCtx)
+	for _, job := range s.Jobs() {
+		slog.Info("tâche de fond planifiée", "job", job.Name, "schedule", job.Schedule.String(), "next", job.Schedule.Next(time.Now()))
+	}
+}
+
+// Stop arrête la planification puis attend la fin des exécutions en cours, interrompues à l'expiration de ctx
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.stopLoop == nil {
+		return nil
+	}
+	s.stopLoop()
+	<-s.done
+
+	//Les exécutions en cours disposent du délai d'arrêt pour se terminer
+	finished := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(finished)
+	}()
+	defer s.cancelRuns()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		s.cancelRuns()
+		<-finished
+		return fmt.Errorf("arrêt des tâches de fond : %w", ctx.Err())
+	}
+}
+
+// loop déclenche chaque tâche à sa prochaine date d'exécution, dans le contexte runCtx, jusqu'à l'annulation de ctx
+func (s *Scheduler) loop(ctx, runCtx context.Context) {
+	defer close(s.done)
+
+	s.mu.Lock()
+	entries := s.entries
+	s.mu.Unlock()
+	if len(entries) == 0 {
+		<-ctx.Done()
+		return
+	}
+
+	now := time.Now()
+	for _, e := range entries {
+		e.next = e.job.Schedule.Next(now)
+	}
+	for {
+		next := entries[0].next
+		for _, e := range entries[1:] {
+			if e.next.Before(next) {
+				next = e.next
+			}
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		now = time.Now()
+		for _, e := range entries {
+			if e.next.After(now) {
+				continue
+			}
+			e.next = e.job.Schedule.Next(now)
+			s.runs.Add(1)
+			go func(e *entry, next time.Time) {
+				defer s.runs.Done()
+				if _, err := s.execute(runCtx, e, TriggerSchedule, next); err != nil && !errors.Is(err, ErrJobBusy) {
+					slog.Error("échec de la tâche de fond", "job", e.job.Name, "error", err)
+				}
+			}(e, e.next)
+		}
+	}
+}
+
+// RunNow exécute immédiatement la tâche name, avec les mêmes garanties qu'une exécution planifiée, et renvoie
+// l'exécution enregistrée dans l'historique
+func (s *Scheduler) RunNow(ctx context.Context, name string) (*models.JobRun, error) {
+	s.mu.Lock()
+	var found *entry
+	for _, e := range s.entries {
+		if e.job.Name == name {
+			found = e
+		}
+	}
+	s.mu.Unlock()
+	if found == nil {
+		return nil, fmt.Errorf("%w : %q", ErrUnknownJob, name)
+	}
+	return s.execute(ctx, found, TriggerManual, time.Time{})
+}
+
+// execute exécute une tâche si ni ce processus ni une autre instance ne l'exécute déjà, puis enregistre son résultat
+// dans l'historique et les métriques. Une exécution planifiée garde le verrou jusqu'à next, sa prochaine date, dans
+// la limite de lockMargin : les autres instances, dont l'horloge peut légèrement différer, n'exécutent pas une seconde
+// fois la même échéance. L'erreur renvoyée est celle de la tâche, ou ErrJobBusy
+func (s *Scheduler) execute(ctx context.Context, e *entry, trigger string, next time.Time) (*models.JobRun, error) {
+	name := e.job.Name
+	if !e.running.CompareAndSwap(false, true) {
+		metrics.ObserveJob(name, metrics.JobSkipped, 0, 0)
+		slog.Warn("tâche de fond ignorée : l'exécution précédente n'est pas terminée", "job", name)
+		return nil, ErrJobBusy
+	}
+	defer e.running.Store(false)
+
+	//Le verrou en base écarte les autres instances ; il expire de lui-même si ce processus s'arrête brutalement
+	start := time.Now()
+	acquired, err := s.store.AcquireJobLock(ctx, name, s.instance, start, start.Add(s.timeout+lockMargin))
+	if err != nil {
+		metrics.ObserveJob(name, metrics.JobFailure, time.Since(start), 0)
+		return nil, fmt.Errorf("verrouillage : %w", err)
+	}
+	if !acquired {
+		metrics.ObserveJob(name, metrics.JobSkipped, 0, 0)
+		slog.Debug("tâche de fond ignorée : exécutée par une autre instance", "job", name)
+		return nil, ErrJobBusy
+	}
+	//L'historique et le verrou sont mis à jour même si ctx est annulé (arrêt du serveur)
+	persistCtx := context.WithoutCancel(ctx)
+	defer func() {
+		until := time.Now()
+		if hold := start.Add(lockMargin); next.After(until) {
+			until = next
+			if until.After(hold) {
+				until = hold
+			}
+		}
+		if err := s.store.ReleaseJobLock(persistCtx, name, s.instance, until); err != nil {
+			slog.Error("libération du verrou de la tâche de fond impossible", "job", name, "error", err)
+		}
+	}()
+
+	run := &models.JobRun{Job: name, Instance: s.instance, Trigger: trigger, Status: models.JobRunning, StartedAt: start}
+	if err := s.store.CreateJobRun(ctx, run); err != nil {
+		metrics.ObserveJob(name, metrics.JobFailure, time.Since(start), 0)
+		return nil, fmt.Errorf("enregistrement de l'exécution : %w", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	items, runErr := safeRun(runCtx, e.job)
+	cancel()
+
+	finished := time.Now()
+	run.FinishedAt, run.Items, run.Status = &finished, items, models.JobSucceeded
+	result := metrics.JobSuccess
+	if runErr != nil {
+		run.Status, run.Error, result = models.JobFailed, truncate(runErr.Error(), 1024), metrics.JobFailure
+	}
+	metrics.ObserveJob(name, result, run.Duration(), items)
+	if err := s.store.UpdateJobRun(persistCtx, run); err != nil {
+		slog.Error("enregistrement de l'exécution impossible", "job", name, "error", err)
+	}
+	if _, err := s.store.PruneJobRuns(persistCtx, name, s.historySize); err != nil {
+		slog.Error("purge de l'historique des tâches de fond impossible", "job", name, "error", err)
+	}
+
+	if runErr != nil {
+		return run, runErr
+	}
+	slog.Info("tâche de fond exécutée", "job", name, "trigger", trigger, "items", items, "duration", run.Duration().String())
+	return run, nil
+}
+
+// safeRun exécute la tâche en convertissant une éventuelle panique en erreur
+func safeRun(ctx context.Context, job Job) (items int64, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panique : %v", recovered)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// truncate limite message à max octets, sans couper de caractère
+func truncate(message string, max int) string {
+	if len(message) <= max {
+		return message
+	}
+	for max > 0 && !utf8.RuneStart(message[max]) {
+		max--
+	}
+	return message[:max]
+}
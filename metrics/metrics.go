@@ -0,0 +1,141 @@
+// Package metrics expose les métriques Prometheus de l'API : requêtes HTTP, requêtes SQL,
+// connexions, tâches de fond, sessions actives et tâches par statut
+package metrics
+
+import (
+	"net/http"
+	"time"
+	"to-do-list-api/store"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "todo"
+
+// Registry regroupe les métriques du processus. Un registre dédié, plutôt que le registre global,
+// évite d'exposer les métriques des dépendances qui s'y enregistrent d'elles-mêmes
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests compte les requêtes par route, méthode et code de réponse
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Nombre de requêtes HTTP traitées, par route, méthode et code de réponse.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPRequestDuration mesure la latence des requêtes par route et méthode
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Durée de traitement des requêtes HTTP, par route et méthode.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// DBQueryDuration mesure la durée des requêtes SQL par opération et table
+	DBQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Durée des requêtes à la base de données, par opération et table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// LoginAttempts compte les tentatives de connexion par mode et résultat
+	LoginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_attempts_total",
+		Help:      "Nombre de tentatives de connexion, par mode (password, oidc, magic_link) et résultat (success, failure, throttled).",
+	}, []string{"method", "result"})
+
+	// JobRuns compte les exécutions des tâches de fond par tâche et résultat
+	JobRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_runs_total",
+		Help:      "Nombre d'exécutions des tâches de fond, par tâche et résultat (success, failure, skipped).",
+	}, []string{"job", "result"})
+
+	// JobDuration mesure la durée des exécutions des tâches de fond
+	JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Durée des exécutions des tâches de fond, par tâche.",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"job"})
+
+	// JobItems compte les éléments traités par les tâches de fond (sessions supprimées, rappels envoyés...)
+	JobItems = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_items_total",
+		Help:      "Nombre d'éléments traités par les tâches de fond, par tâche.",
+	}, []string{"job"})
+
+	// JobLastSuccess date la dernière exécution réussie de chaque tâche de fond
+	JobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_last_success_timestamp_seconds",
+		Help:      "Date (secondes Unix) de la dernière exécution réussie, par tâche de fond.",
+	}, []string{"job"})
+)
+
+// Modes et résultats de connexion (étiquettes de LoginAttempts)
+const (
+	LoginPassword  = "password"
+	LoginOIDC      = "oidc"
+	LoginMagicLink = "magic_link"
+
+	LoginSuccess   = "success"
+	LoginFailure   = "failure"
+	LoginThrottled = "throttled"
+)
+
+// Résultats d'une exécution de tâche de fond (étiquette de JobRuns)
+const (
+	JobSuccess = "success"
+	JobFailure = "failure"
+	JobSkipped = "skipped"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		DBQueryDuration,
+		LoginAttempts,
+		JobRuns,
+		JobDuration,
+		JobItems,
+		JobLastSuccess,
+	)
+}
+
+// ObserveLogin enregistre une tentative de connexion
+func ObserveLogin(method, result string) {
+	LoginAttempts.WithLabelValues(method, result).Inc()
+}
+
+// ObserveJob enregistre une exécution de tâche de fond. Une exécution écartée (skipped) n'a ni durée ni éléments
+func ObserveJob(job, result string, duration time.Duration, items int64) {
+	JobRuns.WithLabelValues(job, result).Inc()
+	if result == JobSkipped {
+		return
+	}
+	JobDuration.WithLabelValues(job).Observe(duration.Seconds())
+	JobItems.WithLabelValues(job).Add(float64(items))
+	if result == JobSuccess {
+		JobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+	}
+}
+
+// Handler expose au format Prometheus les métriques du processus ainsi que celles calculées
+// à partir des dépôts au moment de la collecte (sessions actives, tâches par statut)
+func Handler(s *store.Store) http.Handler {
+	storeRegistry := prometheus.NewRegistry()
+	storeRegistry.MustRegister(newStoreCollector(s))
+
+	gatherers := prometheus.Gatherers{Registry, storeRegistry}
+	return promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{ErrorLog: errorLogger{}})
+}
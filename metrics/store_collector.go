@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"to-do-list-api/store"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Délai maximal accordé aux requêtes des dépôts lors d'une collecte
+const collectTimeout = 5 * time.Second
+
+var (
+	activeSessionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "active_sessions"),
+		"Nombre de sessions non expirées.",
+		nil, nil,
+	)
+	tasksDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "tasks"),
+		"Nombre de tâches, par statut.",
+		[]string{"status"}, nil,
+	)
+)
+
+// storeCollector calcule à chaque collecte les jauges issues des dépôts
+type storeCollector struct {
+	store *store.Store
+}
+
+func newStoreCollector(s *store.Store) *storeCollector {
+	return &storeCollector{store: s}
+}
+
+func (sc *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeSessionsDesc
+	ch <- tasksDesc
+}
+
+func (sc *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	if count, err := sc.store.Sessions.CountActiveSessions(ctx, time.Now()); err != nil {
+		ch <- prometheus.NewInvalidMetric(activeSessionsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(activeSessionsDesc, prometheus.GaugeValue, float64(count))
+	}
+
+	counts, err := sc.store.Tasks.CountTasksByStatus(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(tasksDesc, err)
+		return
+	}
+	//Les statuts sans tâche sont exposés à 0 pour que les séries ne disparaissent pas
+	for _, status := range []string{"to-do", "in-progress", "done"} {
+		if _, exists := counts[status]; !exists {
+			counts[status] = 0
+		}
+	}
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(tasksDesc, prometheus.GaugeValue, float64(count), status)
+	}
+}
+
+// errorLogger transmet les erreurs de collecte au journal du serveur
+type errorLogger struct{}
+
+func (errorLogger) Println(v ...interface{}) {
+	slog.Error("erreur de collecte des métriques", "error", fmt.Sprint(v...))
+}
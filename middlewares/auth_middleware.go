@@ -1,51 +1,126 @@
 package middlewares
 
 import (
-	"net/http"
+	"strconv"
+	"to-do-list-api/apierror"
+	"to-do-list-api/i18n"
 	"to-do-list-api/models"
 	"to-do-list-api/pkg"
+	"to-do-list-api/store"
 
 	"github.com/gin-gonic/gin"
 )
 
-// La fonction AuthRequired génère un middleware qui vérifie qu'un utilisateur a une session valide et qu'il est injecté au contexte
-func AuthRequired() gin.HandlerFunc {
+// La fonction AuthRequired génère un middleware qui vérifie qu'un utilisateur a une session valide et qu'il est injecté au contexte.
+// La session est identifiée par l'en-tête "Authorization: Bearer <jeton>" ou, à défaut, par le cookie session_token
+func AuthRequired(sessions store.SessionStore, users store.UserStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		query := pkg.DB
-		//Lire le cookie de session
-		sessionToken, err := c.Cookie("session_token")
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentification requise"})
-			c.Abort() // pour marquer l'arrêt du traitement de la requête (les middlewares sont chaînés)
-			return
+		ctx := c.Request.Context()
+		//Lire le jeton Bearer, à défaut le cookie de session
+		sessionToken, found := bearerToken(c)
+		if !found {
+			var err error
+			if sessionToken, err = c.Cookie(pkg.SessionCookieName); err != nil {
+				apierror.Respond(c, apierror.CodeUnauthenticated, "auth.required")
+				c.Abort() // pour marquer l'arrêt du traitement de la requête (les middlewares sont chaînés)
+				return
+			}
 		}
 
 		//Vérifier si le token correspond à une session valide
-		var session models.Session
-		if err := query.Where("token = ?", sessionToken).First(&session).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session invalide"})
+		session, err := sessions.GetSession(ctx, sessionToken)
+		if err != nil {
+			apierror.Respond(c, apierror.CodeSessionInvalid, "auth.session_invalid")
 			c.Abort()
 			return
 		}
 
 		//Vérifier si la session a expiré
 		if session.ExpiresAt.Before(pkg.TimeNow()) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expirée"})
+			apierror.Respond(c, apierror.CodeSessionExpired, "auth.session_expired")
 			c.Abort()
 			return
 		}
 
 		//Récupérer l'utilisateur associé à la session
-		var user models.User
-		if err := query.First(&user, session.UserID).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Utilisateur introuvable"})
+		user, err := users.GetUser(ctx, session.UserID)
+		if err != nil {
+			apierror.Respond(c, apierror.CodeInternal, "user.not_found")
+			c.Abort()
+			return
+		}
+		//Les sessions d'un compte désactivé sont fermées ; celle-ci l'aurait été entre-temps
+		if user.IsDisabled() {
+			apierror.Respond(c, apierror.CodeAccountDisabled, "auth.account_disabled")
 			c.Abort()
 			return
 		}
 		//Ajouter au contexte pour une utilisation ultérieure
-		c.Set("currentUser", &user)
+		c.Set("currentUser", user)
+		c.Set("session", session)
+
+		//La langue choisie par l'utilisateur l'emporte sur celle du navigateur
+		if lang, supported := i18n.Parse(user.Language); supported {
+			setLanguage(c, lang)
+		}
 
 		// Continuer vers le prochain middleware ou handler
 		c.Next() //à ajouter pour marquer la continuité du traitement
 	}
 }
+
+// AdminRequired génère un middleware réservant la route aux administrateurs. Il doit être chaîné après AuthRequired
+func AdminRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authentifiedUser, exists := c.Get("currentUser")
+		if !exists {
+			apierror.Respond(c, apierror.CodeUnauthenticated, "auth.unauthenticated")
+			c.Abort()
+			return
+		}
+
+		user, ok := authentifiedUser.(*models.User)
+		if !ok {
+			apierror.Respond(c, apierror.CodeInternal, "internal.current_user")
+			c.Abort()
+			return
+		}
+
+		if !user.IsAdmin {
+			apierror.Respond(c, apierror.CodeAdminRequired, "auth.admin_required")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SelfOrAdminRequired génère un middleware réservant une route /users/:id au titulaire du compte et aux
+// administrateurs. Il doit être chaîné après AuthRequired
+func SelfOrAdminRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authentifiedUser, exists := c.Get("currentUser")
+		if !exists {
+			apierror.Respond(c, apierror.CodeUnauthenticated, "auth.unauthenticated")
+			c.Abort()
+			return
+		}
+
+		user, ok := authentifiedUser.(*models.User)
+		if !ok {
+			apierror.Respond(c, apierror.CodeInternal, "internal.current_user")
+			c.Abort()
+			return
+		}
+
+		//Un identifiant invalide est signalé par le contrôleur, comme pour un administrateur
+		if id, err := strconv.ParseUint(c.Param("id"), 10, 32); err == nil && uint(id) != user.ID && !user.IsAdmin {
+			apierror.Respond(c, apierror.CodeUserForbidden, "user.forbidden")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"to-do-list-api/apierror"
+	"to-do-list-api/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CSRFProtection génère un middleware de protection CSRF par double soumission : le jeton du cookie csrf_token
+// doit être renvoyé dans l'en-tête X-CSRF-Token (ou le champ de formulaire csrf_token) pour toute requête
+// modifiante authentifiée par le cookie de session. Les requêtes authentifiées par un jeton Bearer en sont exemptées,
+// un site tiers ne pouvant pas les forger
+func CSRFProtection() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hasBearerToken(c) {
+			c.Next()
+			return
+		}
+
+		//Déposer un jeton CSRF s'il n'existe pas encore
+		csrfToken, err := c.Cookie(pkg.CSRFCookieName)
+		if err != nil || csrfToken == "" {
+			csrfToken = setCSRFCookie(c)
+		}
+		c.Set("csrfToken", csrfToken) // disponible pour les pages HTML contenant un formulaire
+
+		if isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		//Sans cookie de session, la requête n'est pas authentifiée par le navigateur et ne peut pas être détournée
+		if _, err := c.Cookie(pkg.SessionCookieName); err != nil {
+			c.Next()
+			return
+		}
+
+		submitted := c.GetHeader(pkg.CSRFHeaderName)
+		if submitted == "" {
+			submitted = c.PostForm("csrf_token")
+		}
+		if submitted == "" || subtle.ConstantTimeCompare([]byte(submitted), []byte(csrfToken)) != 1 {
+			apierror.Respond(c, apierror.CodeCSRFInvalid, "request.csrf_invalid")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// setCSRFCookie dépose un nouveau jeton CSRF, lisible par le JavaScript du client, et le renvoie
+func setCSRFCookie(c *gin.Context) string {
+	csrfToken := pkg.GenerateToken()
+	c.SetSameSite(pkg.CookieSameSite)
+	c.SetCookie(pkg.CSRFCookieName, csrfToken, 0, "/", "", pkg.CookieSecure, false)
+	return csrfToken
+}
+
+// hasBearerToken indique si la requête est authentifiée par l'en-tête Authorization
+func hasBearerToken(c *gin.Context) bool {
+	_, found := bearerToken(c)
+	return found
+}
+
+// bearerToken extrait le jeton de l'en-tête "Authorization: Bearer <jeton>"
+func bearerToken(c *gin.Context) (string, bool) {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
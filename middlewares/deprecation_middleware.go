@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deprecated génère un middleware signalant que les routes du groupe sont dépréciées depuis since (en-tête Deprecation,
+// RFC 9745) et seront retirées le sunset (en-tête Sunset, RFC 8594, omis si la date est nulle). L'en-tête Link désigne
+// la même route dans la version qui les remplace, dont successor est le préfixe (ex. "/v1")
+func Deprecated(since, sunset time.Time, successor string) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", since.Unix())
+	sunsetDate := ""
+	if !sunset.IsZero() {
+		sunsetDate = sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunsetDate != "" {
+			c.Header("Sunset", sunsetDate)
+		}
+		c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successor, c.Request.URL.Path))
+		slog.DebugContext(c.Request.Context(), "route dépréciée", "path", c.Request.URL.Path, "successor", successor)
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"to-do-list-api/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Language choisit la langue des messages de la réponse d'après l'en-tête Accept-Language. AuthRequired la remplace
+// ensuite par la langue préférée de l'utilisateur authentifié, si elle est définie
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Language")
+		setLanguage(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// setLanguage fixe la langue de la requête et l'annonce dans l'en-tête Content-Language
+func setLanguage(c *gin.Context, lang i18n.Lang) {
+	c.Request = c.Request.WithContext(i18n.WithLang(c.Request.Context(), lang))
+	c.Header("Content-Language", string(lang))
+}
@@ -0,0 +1,26 @@
+package middlewares
+
+import (
+	"strconv"
+	"time"
+	"to-do-list-api/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics mesure le nombre et la durée des requêtes. Les requêtes sont étiquetées par modèle de route
+// (/tasks/:id) et non par chemin, afin de borner le nombre de séries
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		metrics.HTTPRequests.WithLabelValues(route, method, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, method).Observe(time.Since(start).Seconds())
+	}
+}
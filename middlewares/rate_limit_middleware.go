@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+	"to-do-list-api/apierror"
+	"to-do-list-api/models"
+	"to-do-list-api/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy décrit la limitation appliquée à un groupe de routes
+type RateLimitPolicy struct {
+	Name  string // préfixe des clés, qui isole les compteurs de chaque politique
+	Limit pkg.RateLimit
+	Key   func(c *gin.Context) string // identifie le client ; RateLimitKey par défaut
+}
+
+// RateLimit génère un middleware de limitation par seau de jetons. Il renseigne les en-têtes RateLimit-*
+// et répond 429 avec Retry-After lorsque le seau est vide. Placé après AuthRequired, il limite par utilisateur
+func RateLimit(store pkg.RateLimitStore, policy RateLimitPolicy) gin.HandlerFunc {
+	keyFunc := policy.Key
+	if keyFunc == nil {
+		keyFunc = RateLimitKey
+	}
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit.Capacity(), int(policy.Limit.Period.Seconds()))
+
+	return func(c *gin.Context) {
+		result, err := store.Take(c.Request.Context(), policy.Name+":"+keyFunc(c), policy.Limit)
+		if err != nil {
+			// Une panne du stockage ne doit pas rendre l'API indisponible
+			slog.ErrorContext(c.Request.Context(), "limitation de débit indisponible", "policy", policy.Name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policyHeader)
+		c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit.Capacity()))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			apierror.Respond(c, apierror.CodeRateLimited, "request.rate_limited")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitKey identifie le client : utilisateur authentifié, à défaut adresse IP. Un jeton Bearer n'est pas une clé
+// tant que AuthRequired ne l'a pas validé : sinon, chaque valeur inventée ouvrirait un nouveau quota
+func RateLimitKey(c *gin.Context) string {
+	if authentifiedUser, exists := c.Get("currentUser"); exists {
+		if user, ok := authentifiedUser.(*models.User); ok {
+			return "user:" + strconv.FormatUint(uint64(user.ID), 10)
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
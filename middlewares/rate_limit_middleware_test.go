@@ -0,0 +1,157 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"to-do-list-api/apierror"
+	"to-do-list-api/models"
+	"to-do-list-api/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeClock remplace pkg.TimeNow le temps du test ; advance fait avancer l'horloge
+func fakeClock(t *testing.T) (advance func(time.Duration)) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	previous := pkg.TimeNow
+	pkg.TimeNow = func() time.Time { return now }
+	t.Cleanup(func() { pkg.TimeNow = previous })
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+// rateLimitedRouter limite GET / à 3 requêtes par minute. L'en-tête X-Test-User simule un utilisateur authentifié
+func rateLimitedRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authenticate := func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			userID, _ := strconv.Atoi(id)
+			user := &models.User{}
+			user.ID = uint(userID)
+			c.Set("currentUser", user)
+		}
+	}
+	policy := RateLimitPolicy{Name: "test", Limit: pkg.RateLimit{Requests: 3, Period: time.Minute}}
+	router.GET("/", authenticate, RateLimit(pkg.NewMemoryRateLimitStore(), policy), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+func get(router *gin.Engine, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	advance := fakeClock(t)
+	router := rateLimitedRouter()
+
+	tests := []struct {
+		status    int
+		remaining string
+		reset     string
+		retry     string
+	}{
+		{http.StatusNoContent, "2", "20", ""},
+		{http.StatusNoContent, "1", "40", ""},
+		{http.StatusNoContent, "0", "60", ""},
+		{http.StatusTooManyRequests, "0", "60", "20"},
+	}
+	for i, tt := range tests {
+		w := get(router, "192.0.2.1:1234", nil)
+		if w.Code != tt.status {
+			t.Fatalf("requête %d : statut %d, %d attendu", i+1, w.Code, tt.status)
+		}
+		for name, want := range map[string]string{
+			"RateLimit-Policy":    "3;w=60",
+			"RateLimit-Limit":     "3",
+			"RateLimit-Remaining": tt.remaining,
+			"RateLimit-Reset":     tt.reset,
+			"Retry-After":         tt.retry,
+		} {
+			if got := w.Header().Get(name); got != want {
+				t.Errorf("requête %d : %s = %q, %q attendu", i+1, name, got, want)
+			}
+		}
+	}
+
+	//Le délai d'attente décroît avec le temps, arrondi à la seconde supérieure
+	advance(9500 * time.Millisecond)
+	w := get(router, "192.0.2.1:1234", nil)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "11" || w.Header().Get("RateLimit-Reset") != "51" {
+		t.Errorf("après 9,5 s : statut %d, Retry-After %q, RateLimit-Reset %q ; 429, 11 et 51 attendus",
+			w.Code, w.Header().Get("Retry-After"), w.Header().Get("RateLimit-Reset"))
+	}
+	var problem apierror.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != apierror.CodeRateLimited {
+		t.Errorf("corps %s, code %s attendu", w.Body.String(), apierror.CodeRateLimited)
+	}
+
+	//Un jeton regagné : la requête passe de nouveau
+	advance(10500 * time.Millisecond)
+	if w := get(router, "192.0.2.1:1234", nil); w.Code != http.StatusNoContent {
+		t.Errorf("après 20 s : statut %d, %d attendu", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	fakeClock(t)
+	router := rateLimitedRouter()
+	exhaust := func(remoteAddr string, headers map[string]string) {
+		t.Helper()
+		for range 3 {
+			get(router, remoteAddr, headers)
+		}
+		if w := get(router, remoteAddr, headers); w.Code != http.StatusTooManyRequests {
+			t.Fatalf("quota non épuisé : statut %d", w.Code)
+		}
+	}
+
+	//Sans utilisateur, la clé est l'adresse IP ; un jeton Bearer non validé n'y change rien
+	exhaust("192.0.2.1:1234", nil)
+	if w := get(router, "192.0.2.1:5678", map[string]string{"Authorization": "Bearer invente"}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("jeton non validé : statut %d, %d attendu", w.Code, http.StatusTooManyRequests)
+	}
+	if w := get(router, "192.0.2.2:1234", nil); w.Code != http.StatusNoContent {
+		t.Errorf("autre adresse : statut %d, %d attendu", w.Code, http.StatusNoContent)
+	}
+
+	//Un utilisateur authentifié a son propre quota, quelle que soit son adresse
+	if w := get(router, "192.0.2.1:1234", map[string]string{"X-Test-User": "7"}); w.Code != http.StatusNoContent {
+		t.Errorf("utilisateur depuis une adresse épuisée : statut %d, %d attendu", w.Code, http.StatusNoContent)
+	}
+	exhaust("192.0.2.3:1234", map[string]string{"X-Test-User": "7"})
+	if w := get(router, "192.0.2.4:1234", map[string]string{"X-Test-User": "7"}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("même utilisateur, autre adresse : statut %d, %d attendu", w.Code, http.StatusTooManyRequests)
+	}
+	if w := get(router, "192.0.2.3:1234", map[string]string{"X-Test-User": "8"}); w.Code != http.StatusNoContent {
+		t.Errorf("autre utilisateur : statut %d, %d attendu", w.Code, http.StatusNoContent)
+	}
+}
+
+func TestRateLimitKeyValues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.RemoteAddr = "192.0.2.1:1234"
+	if got := RateLimitKey(c); got != "ip:192.0.2.1" {
+		t.Errorf("RateLimitKey sans utilisateur = %q, ip:192.0.2.1 attendu", got)
+	}
+	user := &models.User{}
+	user.ID = 7
+	c.Set("currentUser", user)
+	if got := RateLimitKey(c); got != "user:7" {
+		t.Errorf("RateLimitKey avec utilisateur = %q, user:7 attendu", got)
+	}
+}
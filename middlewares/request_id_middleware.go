@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"to-do-list-api/pkg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID attribue à chaque requête un identifiant, repris de l'en-tête X-Request-ID s'il est valide
+// (proxy, client) ou généré sinon. Il est renvoyé dans la réponse, ajouté aux logs et aux réponses d'erreur
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(pkg.RequestIDHeader)
+		if !pkg.ValidRequestID(id) {
+			id = pkg.NewRequestID()
+		}
+
+		c.Set("requestID", id)
+		c.Request = c.Request.WithContext(pkg.WithRequestID(c.Request.Context(), id))
+		c.Header(pkg.RequestIDHeader, id)
+		c.Next()
+	}
+}
@@ -1,48 +1,47 @@
 package middlewares
 
 import (
-	"net/http"
 	"strconv"
+	"to-do-list-api/apierror"
 	"to-do-list-api/models"
-	"to-do-list-api/pkg"
+	"to-do-list-api/store"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // AuthorizeTaskOwnerShip génère un middleware vérifiant les permissions d'exécution d'une opération sur une tâche
-func AuthorizeTaskOwnerShip() gin.HandlerFunc {
+func AuthorizeTaskOwnerShip(tasks store.TaskStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Récupérer l'utilisateur authentifié
 		authentifiedUser, exists := c.Get("currentUser")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Utilisateur non authentifié"})
+			apierror.Respond(c, apierror.CodeUnauthenticated, "auth.unauthenticated")
 			c.Abort()
 			return
 		}
 
 		user, ok := authentifiedUser.(*models.User)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Impossible de récupérer l'utilisateur"})
+			apierror.Respond(c, apierror.CodeInternal, "internal.current_user")
 			c.Abort()
 			return
 		}
 
 		// Récupérer l'ID de la tâche depuis les paramètres
-		taskID := c.Param("id")
-		if _, err := strconv.Atoi(taskID); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "L'ID doit être un entier valide"})
+		taskID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			apierror.Respond(c, apierror.CodeInvalidRequest, "request.invalid_id")
 			c.Abort()
 			return
 		}
 		// Vérifier que la tâche existe
-		var task models.Task
-		if err := pkg.DB.First(&task, taskID).Error; err != nil {
-			if err != gorm.ErrRecordNotFound {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Erreur interne lors de la vérification de l'appartenance de la tâche à l'utilisateur"})
+		task, err := tasks.GetTask(c.Request.Context(), uint(taskID))
+		if err != nil {
+			if err != store.ErrNotFound {
+				apierror.Respond(c, apierror.CodeInternal, "internal.task_ownership")
 				c.Abort()
 			} else {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Tâche non trouvée"})
+				apierror.Respond(c, apierror.CodeTaskNotFound, "task.not_found")
 				c.Abort()
 			}
 			return
@@ -50,13 +49,13 @@ func AuthorizeTaskOwnerShip() gin.HandlerFunc {
 
 		//Vérifier qu'elle appartient bien à l'utilisateur authentifié
 		if task.UserID != user.ID {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Dommage pour toi gros, action non autorisée"})
+			apierror.Respond(c, apierror.CodeTaskForbidden, "task.forbidden")
 			c.Abort()
 			return
 		}
 
 		// Ajouter la tâche au contexte pour un usage ultérieur dans le contrôleur
-		c.Set("task", &task) //le pointeur ici évite de dupliquer l'objet en mémoire
+		c.Set("task", task) //le pointeur ici évite de dupliquer l'objet en mémoire
 
 		// Continuer la chaîne des middlewares
 		c.Next()
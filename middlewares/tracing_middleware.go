@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"to-do-list-api/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing ouvre le span serveur de chaque requête, en reprenant le contexte de trace de l'en-tête
+// traceparent s'il est présent. Le span est nommé d'après le modèle de route (GET /tasks/:id)
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = c.Request.Method
+		}
+
+		ctx, span := tracing.Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// Traced enveloppe un middleware ou un contrôleur dans un span portant son nom (AuthRequired, UpdateTask...).
+// Les requêtes SQL qu'il exécute, et les handlers suivants de la chaîne, deviennent des spans enfants
+func Traced(handler gin.HandlerFunc) gin.HandlerFunc {
+	name := handlerName(handler)
+	return func(c *gin.Context) {
+		ctx, span := tracing.Tracer().Start(c.Request.Context(), name)
+		defer span.End()
+
+		parent := c.Request
+		c.Request = c.Request.WithContext(ctx)
+		handler(c)
+		c.Request = parent
+
+		if c.IsAborted() {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(c.Writer.Status()))
+		}
+	}
+}
+
+// handlerName déduit un nom lisible de la fonction : "to-do-list-api/middlewares.AuthRequired.func1" devient
+// "AuthRequired" et "to-do-list-api/controllers.(*Handlers).UpdateTask-fm" devient "UpdateTask"
+func handlerName(handler gin.HandlerFunc) string {
+	fullName := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name := fullName[strings.LastIndex(fullName, "/")+1:]
+	name = strings.TrimSuffix(name, "-fm")
+
+	parts := strings.Split(name, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(parts[i], "func") {
+			return parts[i]
+		}
+	}
+	return fmt.Sprintf("handler %s", fullName)
+}
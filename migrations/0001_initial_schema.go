@@ -0,0 +1,81 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Instantané des modèles au moment de la migration : les migrations ne dépendent pas du package models,
+// dont les structures continueront d'évoluer
+
+type user0001 struct {
+	gorm.Model
+	Username            string `gorm:"size:20;unique;not null"`
+	Email               string `gorm:"size:254;unique;not null"`
+	Password            string `gorm:"not null"`
+	IsAdmin             bool   `gorm:"not null;default:false"`
+	FailedLoginAttempts int    `gorm:"not null;default:0"`
+	LockedUntil         *time.Time
+}
+
+func (user0001) TableName() string { return "users" }
+
+type task0001 struct {
+	gorm.Model
+	Title  string   `gorm:"not null"`
+	Status string   `gorm:"size:16;check:status IN ('to-do','in-progress','done')"`
+	UserID uint     `gorm:"not null"`
+	User   user0001 `gorm:"constraint:OnDelete:CASCADE;foreignKey:UserID;references:ID"`
+}
+
+func (task0001) TableName() string { return "tasks" }
+
+type session0001 struct {
+	ID        uint      `gorm:"primaryKey"`
+	Token     string    `gorm:"size:64;unique;not null"`
+	UserID    uint      `gorm:"unique;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (session0001) TableName() string { return "sessions" }
+
+type externalIdentity0001 struct {
+	ID        uint     `gorm:"primaryKey"`
+	Provider  string   `gorm:"size:64;not null;uniqueIndex:idx_external_identity_subject"`
+	Subject   string   `gorm:"size:255;not null;uniqueIndex:idx_external_identity_subject"`
+	Email     string   `gorm:"size:254"`
+	UserID    uint     `gorm:"not null;index"`
+	User      user0001 `gorm:"constraint:OnDelete:CASCADE;foreignKey:UserID;references:ID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (externalIdentity0001) TableName() string { return "external_identities" }
+
+type magicLink0001 struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"size:64;unique;not null"`
+	UserID    uint      `gorm:"not null;index"`
+	User      user0001  `gorm:"constraint:OnDelete:CASCADE;foreignKey:UserID;references:ID"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (magicLink0001) TableName() string { return "magic_links" }
+
+// initialSchema crée les tables existantes. Elle s'appuie sur AutoMigrate afin d'adopter sans perte
+// les bases créées avant l'introduction des migrations versionnées
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(tx *gorm.DB) error {
+		return tx.AutoMigrate(&user0001{}, &task0001{}, &session0001{}, &externalIdentity0001{}, &magicLink0001{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&magicLink0001{}, &externalIdentity0001{}, &session0001{}, &task0001{}, &user0001{})
+	},
+}
@@ -0,0 +1,16 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// normalizeUserEmails passe en minuscules les emails enregistrés avant leur normalisation à l'écriture.
+// Deux comptes ne différant que par la casse de l'email font échouer la migration : ils doivent être fusionnés à la main
+var normalizeUserEmails = Migration{
+	Version: 2,
+	Name:    "normalize_user_emails",
+	Up: func(tx *gorm.DB) error {
+		return tx.Exec("UPDATE users SET email = LOWER(email) WHERE email <> LOWER(email)").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		return nil // la casse d'origine n'est pas conservée ; les emails en minuscules restent valides
+	},
+}
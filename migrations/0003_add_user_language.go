@@ -0,0 +1,22 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Instantané de la colonne ajoutée par la migration
+type userLanguage0003 struct {
+	Language string `gorm:"size:8;not null;default:''"`
+}
+
+func (userLanguage0003) TableName() string { return "users" }
+
+// addUserLanguage ajoute la langue préférée des utilisateurs. Vide, la langue est négociée à chaque requête
+var addUserLanguage = Migration{
+	Version: 3,
+	Name:    "add_user_language",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&userLanguage0003{}, "Language")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&userLanguage0003{}, "Language")
+	},
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Instantané de la colonne ajoutée par la migration : tableau JSON des étiquettes, vide pour les tâches existantes
+type taskTags0004 struct {
+	Tags string `gorm:"type:text;not null;default:''"`
+}
+
+func (taskTags0004) TableName() string { return "tasks" }
+
+// addTaskTags ajoute les étiquettes des tâches
+var addTaskTags = Migration{
+	Version: 4,
+	Name:    "add_task_tags",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&taskTags0004{}, "Tags")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&taskTags0004{}, "Tags")
+	},
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Instantané de la colonne ajoutée par la migration
+type userDisabledAt0005 struct {
+	DisabledAt *time.Time
+}
+
+func (userDisabledAt0005) TableName() string { return "users" }
+
+// addUserDisabledAt ajoute la date de désactivation des comptes. Nulle, le compte est actif
+var addUserDisabledAt = Migration{
+	Version: 5,
+	Name:    "add_user_disabled_at",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&userDisabledAt0005{}, "DisabledAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&userDisabledAt0005{}, "DisabledAt")
+	},
+}
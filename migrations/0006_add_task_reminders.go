@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Instantané des colonnes ajoutées par la migration
+type taskReminder0006 struct {
+	RemindAt   *time.Time `gorm:"index"`
+	RemindedAt *time.Time
+}
+
+func (taskReminder0006) TableName() string { return "tasks" }
+
+// addTaskReminders ajoute la date de rappel des tâches et celle de son envoi
+var addTaskReminders = Migration{
+	Version: 6,
+	Name:    "add_task_reminders",
+	Up: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		for _, column := range []string{"RemindAt", "RemindedAt"} {
+			if err := migrator.AddColumn(&taskReminder0006{}, column); err != nil {
+				return err
+			}
+		}
+		return migrator.CreateIndex(&taskReminder0006{}, "RemindAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		migrator := tx.Migrator()
+		if err := migrator.DropIndex(&taskReminder0006{}, "RemindAt"); err != nil {
+			return err
+		}
+		for _, column := range []string{"RemindedAt", "RemindAt"} {
+			if err := migrator.DropColumn(&taskReminder0006{}, column); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
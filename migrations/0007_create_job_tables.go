@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Instantané des tables créées par la migration
+
+type jobRun0007 struct {
+	ID         uint      `gorm:"primaryKey"`
+	Job        string    `gorm:"size:64;not null;index"`
+	Instance   string    `gorm:"size:128;not null"`
+	Trigger    string    `gorm:"size:16;not null"`
+	Status     string    `gorm:"size:16;not null"`
+	Items      int64     `gorm:"not null;default:0"`
+	Error      string    `gorm:"size:1024;not null;default:''"`
+	StartedAt  time.Time `gorm:"not null"`
+	FinishedAt *time.Time
+}
+
+func (jobRun0007) TableName() string { return "job_runs" }
+
+type jobLock0007 struct {
+	Name        string    `gorm:"primaryKey;size:64"`
+	Owner       string    `gorm:"size:128;not null"`
+	LockedUntil time.Time `gorm:"not null"`
+}
+
+func (jobLock0007) TableName() string { return "job_locks" }
+
+// createJobTables crée l'historique des exécutions des tâches de fond et leurs verrous
+var createJobTables = Migration{
+	Version: 7,
+	Name:    "create_job_tables",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&jobRun0007{}, &jobLock0007{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&jobLock0007{}, &jobRun0007{})
+	},
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Instantané de la colonne ajoutée par la migration
+type magicLinkEmail0008 struct {
+	Email string `gorm:"size:254;not null;default:''"`
+}
+
+func (magicLinkEmail0008) TableName() string { return "magic_links" }
+
+// addMagicLinkEmail ajoute aux liens à usage unique l'adresse dont ils confirment le changement
+var addMagicLinkEmail = Migration{
+	Version: 8,
+	Name:    "add_magic_link_email",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&magicLinkEmail0008{}, "Email")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&magicLinkEmail0008{}, "Email")
+	},
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Instantané de la colonne ajoutée par la migration
+type taskReminderAttempts0009 struct {
+	ReminderAttempts int `gorm:"not null;default:0"`
+}
+
+func (taskReminderAttempts0009) TableName() string { return "tasks" }
+
+// addTaskReminderAttempts ajoute aux tâches le nombre d'envois de leur rappel en échec
+var addTaskReminderAttempts = Migration{
+	Version: 9,
+	Name:    "add_task_reminder_attempts",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&taskReminderAttempts0009{}, "ReminderAttempts")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&taskReminderAttempts0009{}, "ReminderAttempts")
+	},
+}
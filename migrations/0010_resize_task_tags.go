@@ -0,0 +1,30 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Instantané de la colonne après la migration : varchar(512) contient les 10 étiquettes de 32 caractères au plus
+// (351 caractères en JSON), et accepte une valeur par défaut sur MySQL, contrairement à TEXT
+type taskTags0010 struct {
+	Tags string `gorm:"type:varchar(512);not null;default:''"`
+}
+
+func (taskTags0010) TableName() string { return "tasks" }
+
+// resizeTaskTags remplace le type text des étiquettes (migration 4) par un varchar borné. SQLite n'impose pas la taille
+// d'un varchar et ne modifie une colonne qu'en reconstruisant la table, ce qui perdrait ses index : il est laissé tel quel
+var resizeTaskTags = Migration{
+	Version: 10,
+	Name:    "resize_task_tags",
+	Up: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "sqlite" {
+			return nil
+		}
+		return tx.Migrator().AlterColumn(&taskTags0010{}, "Tags")
+	},
+	Down: func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "sqlite" {
+			return nil
+		}
+		return tx.Migrator().AlterColumn(&taskTags0004{}, "Tags")
+	},
+}
@@ -1,15 +1,256 @@
 package migrations
 
 import (
-	"to-do-list-api/models"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&models.User{},
-		&models.Task{},
-		&models.Session{},
-	)
+// Migration est une évolution versionnée du schéma. Up l'applique, Down l'annule ; chacune s'exécute
+// dans une transaction avec l'enregistrement correspondant dans schema_migrations
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// All liste les migrations dans l'ordre d'application. Une migration publiée ne doit plus être modifiée :
+// toute évolution du schéma passe par une nouvelle migration, ajoutée en fin de liste
+var All = []Migration{
+	initialSchema,
+	normalizeUserEmails,
+	addUserLanguage,
+	addTaskTags,
+	addUserDisabledAt,
+	addTaskReminders,
+	createJobTables,
+	addMagicLinkEmail,
+	addTaskReminderAttempts,
+	resizeTaskTags,
+}
+
+// ErrSchemaBehind indique que des migrations connues du binaire n'ont pas encore été appliquées
+var ErrSchemaBehind = errors.New("schéma de la base de données en retard")
+
+// schemaMigration est une ligne de la table schema_migrations (une par migration appliquée)
+type schemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// MigrationStatus décrit l'état d'une migration dans la base
+type MigrationStatus struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time // nil si la migration est en attente
+	Unknown   bool       // appliquée en base mais absente de ce binaire
+}
+
+// Latest renvoie la version la plus récente connue du binaire
+func Latest() uint {
+	if len(All) == 0 {
+		return 0
+	}
+	return All[len(All)-1].Version
+}
+
+// Up applique toutes les migrations en attente
+func Up(db *gorm.DB) error {
+	return To(db, Latest())
+}
+
+// Down annule les steps dernières migrations appliquées
+func Down(db *gorm.DB, steps int) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+	if steps <= 0 || len(applied) == 0 {
+		return nil
+	}
+
+	target := uint(0)
+	if steps < len(applied) {
+		target = applied[len(applied)-steps-1]
+	}
+	return To(db, target)
+}
+
+// To amène le schéma exactement à la version demandée, en appliquant ou en annulant les migrations nécessaires.
+// La version 0 correspond à une base vide
+func To(db *gorm.DB, target uint) error {
+	if target != 0 && find(target) == nil {
+		return fmt.Errorf("version de migration inconnue : %d", target)
+	}
+
+	records, err := appliedRecords(db)
+	if err != nil {
+		return err
+	}
+
+	//Les migrations inconnues de ce binaire ne peuvent pas être annulées
+	for version := range records {
+		if version > target && find(version) == nil {
+			return fmt.Errorf("la migration %d appliquée en base est inconnue de ce binaire", version)
+		}
+	}
+
+	//Annuler, de la plus récente à la plus ancienne, les migrations au-delà de la cible
+	for i := len(All) - 1; i >= 0; i-- {
+		m := All[i]
+		if _, applied := records[m.Version]; applied && m.Version > target {
+			if err := run(db, m, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	//Appliquer, dans l'ordre, les migrations en attente jusqu'à la cible
+	for _, m := range All {
+		if _, applied := records[m.Version]; !applied && m.Version <= target {
+			if err := run(db, m, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Status renvoie l'état de chaque migration, connue du binaire ou seulement présente en base. La lecture ne modifie
+// pas la base : sans table schema_migrations, toutes les migrations sont en attente
+func Status(db *gorm.DB) ([]MigrationStatus, error) {
+	records, err := readRecords(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(All))
+	for _, m := range All {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, applied := records[m.Version]; applied {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(records, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range records {
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{Version: record.Version, Name: record.Name, AppliedAt: &appliedAt, Unknown: true})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// CheckCurrent renvoie ErrSchemaBehind si des migrations sont en attente. Appelée par la sonde de disponibilité,
+// elle ne fait que lire la base
+func CheckCurrent(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return fmt.Errorf("%w : base non migrée, exécutez la commande `migrate up`", ErrSchemaBehind)
+	}
+
+	statuses, err := Status(db)
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		} else if status.Unknown {
+			slog.Warn("migration appliquée en base mais inconnue de ce binaire", "version", status.Version, "name", status.Name)
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w : %d migration(s) en attente, exécutez la commande `migrate up`", ErrSchemaBehind, pending)
+	}
+	return nil
+}
+
+// run applique ou annule une migration et met à jour schema_migrations dans la même transaction
+func run(db *gorm.DB, m Migration, up bool) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if !up {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{}, m.Version).Error
+		}
+
+		if err := m.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()}).Error
+	})
+
+	action := "appliquée"
+	if !up {
+		action = "annulée"
+	}
+	if err != nil {
+		return fmt.Errorf("migration %04d_%s non %s : %w", m.Version, m.Name, action, err)
+	}
+	slog.Info("migration "+action, "version", m.Version, "name", m.Name)
+	return nil
+}
+
+// appliedRecords lit schema_migrations, en créant la table si nécessaire
+func appliedRecords(db *gorm.DB) (map[uint]schemaMigration, error) {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+	return readRecords(db)
+}
+
+// readRecords lit schema_migrations sans modifier la base ; une table absente ne contient aucune migration
+func readRecords(db *gorm.DB) (map[uint]schemaMigration, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return map[uint]schemaMigration{}, nil
+	}
+
+	var rows []schemaMigration
+	if err := db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	records := make(map[uint]schemaMigration, len(rows))
+	for _, row := range rows {
+		records[row.Version] = row
+	}
+	return records, nil
+}
+
+// appliedVersions renvoie les versions appliquées, par ordre croissant
+func appliedVersions(db *gorm.DB) ([]uint, error) {
+	records, err := appliedRecords(db)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]uint, 0, len(records))
+	for version := range records {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+// find renvoie la migration de version donnée, ou nil
+func find(version uint) *Migration {
+	for i := range All {
+		if All[i].Version == version {
+			return &All[i]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ExternalIdentity relie un utilisateur à son identité chez un fournisseur OpenID Connect
+type ExternalIdentity struct {
+	ID        uint   `gorm:"primaryKey"`
+	Provider  string `gorm:"size:64;not null;uniqueIndex:idx_external_identity_subject"`
+	Subject   string `gorm:"size:255;not null;uniqueIndex:idx_external_identity_subject"` // claim "sub" de l'ID token
+	Email     string `gorm:"size:254"`
+	UserID    uint `gorm:"not null;index"`
+	User      User `gorm:"constraint:OnDelete:CASCADE;foreignKey:UserID;references:ID"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
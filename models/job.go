@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// États d'une exécution de tâche de fond
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobRun est une exécution d'une tâche de fond planifiée (historique)
+type JobRun struct {
+	ID         uint      `gorm:"primaryKey"`
+	Job        string    `gorm:"size:64;not null;index"`
+	Instance   string    `gorm:"size:128;not null"` // processus qui a exécuté la tâche
+	Trigger    string    `gorm:"size:16;not null"`  // schedule ou manual
+	Status     string    `gorm:"size:16;not null"`
+	Items      int64     `gorm:"not null;default:0"` // éléments traités (sessions supprimées, rappels envoyés...)
+	Error      string    `gorm:"size:1024;not null;default:''"`
+	StartedAt  time.Time `gorm:"not null"`
+	FinishedAt *time.Time
+}
+
+// Duration renvoie la durée de l'exécution, nulle si elle n'est pas terminée
+func (r *JobRun) Duration() time.Duration {
+	if r.FinishedAt == nil {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// JobLock réserve une tâche de fond à une seule instance jusqu'à LockedUntil. La ligne est conservée d'une exécution
+// à l'autre : libérer le verrou revient à avancer LockedUntil
+type JobLock struct {
+	Name        string    `gorm:"primaryKey;size:64"`
+	Owner       string    `gorm:"size:128;not null"`
+	LockedUntil time.Time `gorm:"not null"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// MagicLink est un lien de connexion sans mot de passe, à usage unique. Lorsque Email est renseigné, le lien confirme
+// aussi le changement d'adresse de l'utilisateur : il a été envoyé à cette nouvelle adresse
+type MagicLink struct {
+	ID        uint      `gorm:"primaryKey"`
+	TokenHash string    `gorm:"size:64;unique;not null"` // empreinte SHA-256 du jeton, le jeton lui-même n'est jamais stocké
+	UserID    uint      `gorm:"not null;index"`
+	Email     string    `gorm:"size:254;not null;default:''"` // nouvelle adresse à confirmer, vide pour un lien de connexion
+	User      User      `gorm:"constraint:OnDelete:CASCADE;foreignKey:UserID;references:ID"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -4,7 +4,7 @@ import "time"
 
 type Session struct {
 	ID        uint      `gorm:"primaryKey"`
-	Token     string    `gorm:"unique;not null"`
+	Token     string    `gorm:"size:64;unique;not null"`
 	UserID    uint      `gorm:"unique;not null"`
 	ExpiresAt time.Time `gorm:"not null"`
 	CreatedAt time.Time
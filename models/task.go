@@ -1,15 +1,24 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
-// Task représente une tâche dans le système
+// Task représente une tâche dans le système. Supprimée, elle reste dans la corbeille (DeletedAt) jusqu'à sa purge
 
 	type Task struct {
 		gorm.Model
-		Title  string `gorm:"not null" json:"title"`
-		Status string `gorm:"check:status IN ('to-do','in-progress','done')" json:"status"`
-		UserID uint   `gorm:"not null" json:"user_id"` // Clé étrangère
-		User   User   `gorm:"constraint:OnDelete:CASCADE;foreignKey:UserID; references:ID" json:"-"`
+		Title  string   `gorm:"not null" json:"title"`
+		Status string   `gorm:"size:16;check:status IN ('to-do','in-progress','done')" json:"status"`
+		UserID uint     `gorm:"not null" json:"user_id"` // Clé étrangère
+		User   User     `gorm:"constraint:OnDelete:CASCADE;foreignKey:UserID; references:ID" json:"-"`
+		Tags   []string `gorm:"serializer:json;not null;default:''" json:"tags"`
+
+		// Rappel envoyé par email à la date RemindAt (tâche de fond reminders) ; RemindedAt date son envoi,
+		// ReminderAttempts compte les envois en échec
+		RemindAt         *time.Time `gorm:"index" json:"remind_at"`
+		RemindedAt       *time.Time `json:"-"`
+		ReminderAttempts int        `gorm:"not null;default:0" json:"-"`
 	}
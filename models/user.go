@@ -1,11 +1,34 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // User représente un utilisateur dans le système
 type User struct {
 	gorm.Model
-	Username string `gorm:"unique;not null" json:"username"`
-	Email    string `gorm:"unique;not null" json:"email"`
-	Password string `gorm:"not null" json:"password"`
+	Username string `gorm:"size:20;unique;not null" json:"username"`
+	Email    string `gorm:"size:254;unique;not null" json:"email"` // toujours en minuscules (pkg.NormalizeEmail)
+	Password string `gorm:"not null" json:"-"`                     // empreinte Argon2id, jamais sérialisée
+	IsAdmin  bool   `gorm:"not null;default:false" json:"is_admin"`
+	Language string `gorm:"size:8;not null;default:''" json:"language"` // langue préférée (i18n.Lang), vide si non choisie
+
+	// Protection contre la force brute
+	FailedLoginAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil         *time.Time `json:"-"`
+
+	// Compte désactivé par un administrateur (commande user disable) : aucune session ne peut être ouverte
+	DisabledAt *time.Time `json:"-"`
+}
+
+// IsDisabled indique si le compte a été désactivé
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// IsLocked indique si le compte refuse temporairement les tentatives de connexion
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && u.LockedUntil.After(now)
 }
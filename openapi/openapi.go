@@ -0,0 +1,102 @@
+// Package openapi publie la description OpenAPI 3.1 de l'API (openapi.yaml, maintenue à la main à côté des routes)
+// avec l'interface Swagger UI. Le test de contrat (routes/contract_test.go) vérifie que les réponses réelles du routeur
+// respectent ce document
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"to-do-list-api/apierror"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+	specErr  error
+)
+
+// YAML renvoie le document tel qu'il est maintenu
+func YAML() []byte {
+	return specYAML
+}
+
+// JSON renvoie le document converti en JSON
+func JSON() ([]byte, error) {
+	specOnce.Do(func() {
+		var document any
+		if specErr = yaml.Unmarshal(specYAML, &document); specErr != nil {
+			specErr = fmt.Errorf("openapi.yaml invalide : %w", specErr)
+			return
+		}
+		document, specErr = jsonCompatible(document)
+		if specErr == nil {
+			specJSON, specErr = json.Marshal(document)
+		}
+	})
+	return specJSON, specErr
+}
+
+// jsonCompatible convertit les tables YAML à clés quelconques en objets JSON, dont les clés sont des chaînes
+func jsonCompatible(value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	case map[any]any:
+		object := make(map[string]any, len(v))
+		for key, item := range v {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("openapi.yaml : clé %v non textuelle (mettre les codes de statut entre guillemets)", key)
+			}
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = converted
+		}
+		return object, nil
+	case []any:
+		for i, item := range v {
+			converted, err := jsonCompatible(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// ServeJSON sert le document en JSON (GET /openapi.json)
+func ServeJSON(c *gin.Context) {
+	document, err := JSON()
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "document OpenAPI illisible", "error", err)
+		apierror.Respond(c, apierror.CodeInternal, "internal.server")
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+}
+
+// ServeYAML sert le document tel qu'il est maintenu (GET /openapi.yaml)
+func ServeYAML(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", specYAML)
+}
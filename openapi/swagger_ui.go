@@ -0,0 +1,46 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// Page d'accueil de Swagger UI (version 5, compatible OpenAPI 3.1), pointant vers /openapi.json
+const swaggerIndex = `<!DOCTYPE html>
+<html lang="fr">
+<head>
+<meta charset="utf-8">
+<title>To-Do List API</title>
+<link rel="stylesheet" href="swagger-ui.css">
+<link rel="icon" type="image/png" href="favicon-32x32.png" sizes="32x32">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="swagger-ui-bundle.js"></script>
+<script src="swagger-ui-standalone-preset.js"></script>
+<script>
+window.ui = SwaggerUIBundle({
+  url: "/openapi.json",
+  dom_id: "#swagger-ui",
+  deepLinking: true,
+  presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+  layout: "StandaloneLayout"
+});
+</script>
+</body>
+</html>`
+
+var swaggerAssets = http.FS(swaggerFiles.FS)
+
+// SwaggerUI sert l'interface Swagger UI sur /swagger/*file : la page d'accueil, puis ses fichiers statiques
+func SwaggerUI(c *gin.Context) {
+	file := strings.TrimPrefix(c.Param("file"), "/")
+	if file == "" || file == "index.html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerIndex))
+		return
+	}
+	c.FileFromFS(file, swaggerAssets)
+}
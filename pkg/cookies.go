@@ -0,0 +1,36 @@
+package pkg
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Noms des cookies posés par l'API
+const (
+	SessionCookieName = "session_token"
+	CSRFCookieName    = "csrf_token"
+	CSRFHeaderName    = "X-CSRF-Token"
+)
+
+// Paramètres des sessions et attributs appliqués aux cookies de l'API
+var (
+	SessionTTL     = 24 * time.Hour       // durée de validité d'une session
+	CookieSecure   = true                 // cookies transmis uniquement en HTTPS
+	CookieSameSite = http.SameSiteLaxMode // politique SameSite des cookies de session et CSRF
+)
+
+// ParseSameSite convertit "lax", "strict" ou "none" en politique SameSite
+func ParseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(value) {
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return http.SameSiteDefaultMode, fmt.Errorf("politique SameSite inconnue : %q (attendu lax, strict ou none)", value)
+	}
+}
@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Journalisation SQL, réglée par la configuration (log.sql et log.slow_query)
+var (
+	SQLLogLevel        = logger.Warn
+	SlowQueryThreshold = 200 * time.Millisecond
+)
+
+// ParseSQLLogLevel convertit le niveau de journalisation SQL :
+// silent (rien), error (requêtes en échec), warn (et requêtes lentes), info (toutes les requêtes)
+func ParseSQLLogLevel(name string) (logger.LogLevel, error) {
+	switch strings.ToLower(name) {
+	case "silent":
+		return logger.Silent, nil
+	case "error":
+		return logger.Error, nil
+	case "warn":
+		return logger.Warn, nil
+	case "info":
+		return logger.Info, nil
+	}
+	return 0, fmt.Errorf("niveau de journalisation SQL inconnu : %q (silent, error, warn ou info)", name)
+}
+
+// sqlLogger transmet les messages de GORM au logger slog, avec l'identifiant de la requête HTTP
+// lorsque la requête SQL est exécutée avec son contexte
+type sqlLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newSQLLogger() logger.Interface {
+	return &sqlLogger{level: SQLLogLevel, slowThreshold: SlowQueryThreshold}
+}
+
+func (l *sqlLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *sqlLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+	}
+}
+
+func (l *sqlLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+	}
+}
+
+func (l *sqlLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...), "component", "gorm")
+	}
+}
+
+// Trace journalise une requête exécutée selon le niveau : en échec, lente, ou toutes
+func (l *sqlLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	attrs := func() []any {
+		sql, rows := fc()
+		return []any{"component", "gorm", "sql", sql, "rows", rows, "duration_ms", float64(elapsed.Microseconds()) / 1000}
+	}
+
+	switch {
+	//Un enregistrement introuvable est un cas normal, traité par les dépôts
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		slog.ErrorContext(ctx, "requête SQL en échec", append(attrs(), "error", err)...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		slog.WarnContext(ctx, "requête SQL lente", attrs()...)
+	case l.level >= logger.Info:
+		slog.InfoContext(ctx, "requête SQL", attrs()...)
+	}
+}
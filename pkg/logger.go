@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Formats de journalisation pris en charge
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// ParseLogLevel convertit un niveau de journalisation (debug, info, warn, error)
+func ParseLogLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToLower(name))); err != nil {
+		return 0, fmt.Errorf("niveau de journalisation inconnu : %q (debug, info, warn ou error)", name)
+	}
+	return level, nil
+}
+
+// ConfigureLogger installe le logger slog par défaut, qui reçoit aussi les messages du package log.
+// Chaque ligne journalisée avec un contexte de requête porte son identifiant (request_id) et, si la requête
+// est tracée, les identifiants de trace et de span (trace_id, span_id)
+func ConfigureLogger(w io.Writer, levelName, format string) error {
+	level, err := ParseLogLevel(levelName)
+	if err != nil {
+		return err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format {
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	case LogFormatText:
+		handler = slog.NewTextHandler(w, options)
+	default:
+		return fmt.Errorf("format de journalisation inconnu : %q (json ou text)", format)
+	}
+
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// Fatal journalise une erreur puis arrête le processus
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// contextHandler ajoute aux enregistrements les identifiants de requête et de trace portés par leur contexte
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,119 @@
+package pkg
+
+import (
+	"sync"
+	"time"
+)
+
+// Paramètres de la protection contre la force brute sur /auth/login
+var (
+	LoginFreeAttempts     = 3                // nombre d'échecs tolérés avant d'imposer un délai
+	LoginBaseDelay        = time.Second      // premier délai imposé, doublé à chaque nouvel échec
+	LoginMaxDelay         = 15 * time.Minute // plafond du délai exponentiel
+	LoginLockoutThreshold = 10               // nombre d'échecs consécutifs entraînant le verrouillage
+	LoginLockoutDuration  = time.Hour        // durée du verrouillage temporaire
+)
+
+// LoginBackoff calcule le délai à respecter après un nombre donné d'échecs consécutifs
+func LoginBackoff(failures int) time.Duration {
+	if failures >= LoginLockoutThreshold {
+		return LoginLockoutDuration
+	}
+	if failures < LoginFreeAttempts {
+		return 0
+	}
+
+	delay := LoginBaseDelay << (failures - LoginFreeAttempts)
+	if delay <= 0 || delay > LoginMaxDelay {
+		delay = LoginMaxDelay
+	}
+	return delay
+}
+
+// AttemptLimiter compte en mémoire les échecs de connexion par clé (adresse IP, email...)
+type AttemptLimiter struct {
+	mu        sync.Mutex
+	entries   map[string]*attemptEntry
+	lastSweep time.Time
+}
+
+type attemptEntry struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// LoginAttemptsByIP suit les échecs de connexion par adresse IP cliente
+var LoginAttemptsByIP = NewAttemptLimiter()
+
+// LoginAttemptsByUnknownEmail suit les échecs de connexion sur les emails sans compte, avec les mêmes délais que le
+// verrouillage d'un compte : la réponse ne révèle pas si l'email existe
+var LoginAttemptsByUnknownEmail = NewAttemptLimiter()
+
+func NewAttemptLimiter() *AttemptLimiter {
+	return &AttemptLimiter{entries: make(map[string]*attemptEntry)}
+}
+
+// Blocked indique si la clé doit encore patienter, et combien de temps
+func (l *AttemptLimiter) Blocked(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, exists := l.entries[key]
+	if !exists {
+		return 0, false
+	}
+
+	wait := entry.blockedUntil.Sub(TimeNow())
+	if wait <= 0 {
+		return 0, false
+	}
+	return wait, true
+}
+
+// Fail enregistre un échec pour la clé et renvoie le délai imposé avant la prochaine tentative
+func (l *AttemptLimiter) Fail(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := TimeNow()
+	l.sweep(now)
+
+	entry, exists := l.entries[key]
+	if !exists {
+		entry = &attemptEntry{}
+		l.entries[key] = entry
+	} else if now.Sub(entry.lastFailure) > LoginLockoutDuration {
+		entry.failures = 0 // les anciens échecs ne comptent plus
+	}
+
+	entry.failures++
+	entry.lastFailure = now
+	delay := LoginBackoff(entry.failures)
+	entry.blockedUntil = now.Add(delay)
+
+	return delay
+}
+
+// Reset oublie les échecs enregistrés pour la clé
+func (l *AttemptLimiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+// sweep supprime les entrées inactives depuis plus longtemps que la durée de verrouillage,
+// afin que le compteur redescende de lui-même et que la map ne grossisse pas indéfiniment
+func (l *AttemptLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, entry := range l.entries {
+		if now.Sub(entry.lastFailure) > LoginLockoutDuration && now.After(entry.blockedUntil) {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Paramètres des liens de connexion sans mot de passe
+var (
+	MagicLinkTTL     = 15 * time.Minute        // durée de validité d'un lien
+	MagicLinkBaseURL = "http://localhost:8080" // URL publique de l'API, utilisée pour construire les liens
+	MagicLinkSecret  []byte                    // clé de signature HMAC des liens
+)
+
+// MagicLinkRequestsByEmail limite les demandes de lien par adresse email
+var MagicLinkRequestsByEmail = NewAttemptLimiter()
+
+var (
+	ErrMagicLinkInvalid = errors.New("lien de connexion invalide")
+	ErrMagicLinkExpired = errors.New("lien de connexion expiré")
+)
+
+// ConfigureMagicLink applique les paramètres des liens de connexion. Sans secret, une clé aléatoire
+// est générée : les liens émis ne survivent alors pas à un redémarrage
+func ConfigureMagicLink(secret, baseURL string, ttl time.Duration) error {
+	MagicLinkBaseURL = strings.TrimRight(baseURL, "/")
+	MagicLinkTTL = ttl
+
+	if secret != "" {
+		MagicLinkSecret = []byte(secret)
+		return nil
+	}
+
+	MagicLinkSecret = make([]byte, 32)
+	if _, err := rand.Read(MagicLinkSecret); err != nil {
+		return err
+	}
+	slog.Warn("magic_link.secret non défini : clé de signature des liens de connexion générée aléatoirement")
+	return nil
+}
+
+// NewMagicLinkToken génère un jeton signé "<aléa>.<expiration>.<signature>" et l'empreinte de la partie aléatoire à stocker
+func NewMagicLinkToken(expiresAt time.Time) (token string, hash string) {
+	nonce := GenerateToken()
+	payload := nonce + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + signMagicLink(payload), HashMagicLinkNonce(nonce)
+}
+
+// ParseMagicLinkToken vérifie la signature et l'expiration d'un jeton et renvoie l'empreinte à rechercher en base
+func ParseMagicLinkToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrMagicLinkInvalid
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signMagicLink(payload))) {
+		return "", ErrMagicLinkInvalid
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrMagicLinkInvalid
+	}
+	if TimeNow().After(time.Unix(expiresAt, 0)) {
+		return "", ErrMagicLinkExpired
+	}
+
+	return HashMagicLinkNonce(parts[0]), nil
+}
+
+// HashMagicLinkNonce calcule l'empreinte SHA-256 stockée en base
+func HashMagicLinkNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+func signMagicLink(payload string) string {
+	mac := hmac.New(sha256.New, MagicLinkSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,83 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// Mail est un email à envoyer
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer est l'interface d'envoi d'emails, pour pouvoir changer de transport (SMTP, service tiers, tests...)
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// DefaultMailer est le transport utilisé par l'API. Par défaut les emails sont simplement journalisés
+var DefaultMailer Mailer = LogMailer{}
+
+// LogMailer écrit les emails dans les logs au lieu de les envoyer (développement)
+type LogMailer struct{}
+
+func (LogMailer) Send(ctx context.Context, mail Mail) error {
+	slog.InfoContext(ctx, "email journalisé au lieu d'être envoyé", "to", mail.To, "subject", mail.Subject, "body", mail.Body)
+	return nil
+}
+
+// SMTPMailer envoie les emails via un serveur SMTP
+type SMTPMailer struct {
+	Addr     string // hôte:port
+	From     string
+	Username string
+	Password string
+}
+
+func (m SMTPMailer) Send(_ context.Context, mail Mail) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	// Les en-têtes ne doivent pas contenir de retour à la ligne (injection d'en-têtes)
+	if strings.ContainsAny(mail.To+mail.Subject, "\r\n") {
+		return fmt.Errorf("en-tête d'email invalide")
+	}
+
+	message, err := m.message(mail)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.Addr, auth, m.From, []string{mail.To}, message)
+}
+
+// message construit l'email au format MIME : sujet encodé selon la RFC 2047 et corps en quoted-printable, pour que
+// les caractères accentués arrivent intacts quel que soit le serveur
+func (m SMTPMailer) message(mail Mail) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\nTo: %s\r\nSubject: %s\r\n", m.From, mail.To, mime.QEncoding.Encode("utf-8", mail.Subject))
+	buf.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(mail.Body)); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
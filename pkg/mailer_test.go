@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestSMTPMailerMessage(t *testing.T) {
+	mailer := SMTPMailer{From: "noreply@example.com"}
+	body := "Bonjour Zoé,\n\nVotre tâche « Préparer l'été » arrive à échéance.\n" + strings.Repeat("é", 100) + "\n"
+	raw, err := mailer.message(Mail{To: "zoe@example.com", Subject: "Rappel : tâche à échéance", Body: body})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Le message brut ne contient que de l'ASCII, en lignes d'au plus 78 caractères
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 78 {
+			t.Errorf("ligne de %d caractères : %q", len(line), line)
+		}
+		for _, r := range line {
+			if r > 127 {
+				t.Fatalf("caractère non ASCII dans %q", line)
+			}
+		}
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != "Rappel : tâche à échéance" {
+		t.Errorf("sujet %q (%v)", subject, err)
+	}
+	if got := message.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type %q", got)
+	}
+	if got := message.Header.Get("Content-Transfer-Encoding"); got != "quoted-printable" {
+		t.Errorf("Content-Transfer-Encoding %q", got)
+	}
+	decoded, err := io.ReadAll(quotedprintable.NewReader(message.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.ReplaceAll(string(decoded), "\r\n", "\n"); got != body {
+		t.Errorf("corps décodé %q, %q attendu", got, body)
+	}
+}
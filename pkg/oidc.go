@@ -0,0 +1,123 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// OIDCProviderConfig décrit un fournisseur d'identité OpenID Connect
+type OIDCProviderConfig struct {
+	Name              string // identifiant utilisé dans les routes /auth/oidc/:provider
+	IssuerURL         string
+	ClientID          string
+	ClientSecret      string
+	RedirectURL       string // URL de /auth/oidc/:provider/callback vue par le navigateur
+	Scopes            []string
+	AllowSignup       bool // création d'un compte à la première connexion
+	LinkVerifiedEmail bool // rattachement à un compte existant portant le même email vérifié
+}
+
+// OIDCProvider est un fournisseur configuré. La découverte (.well-known/openid-configuration)
+// est effectuée à la première utilisation, pour ne pas bloquer le démarrage si le fournisseur est indisponible
+type OIDCProvider struct {
+	Config OIDCProviderConfig
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// OIDCProviders contient les fournisseurs configurés, indexés par nom
+var OIDCProviders = map[string]*OIDCProvider{}
+
+// RegisterOIDCProvider ajoute un fournisseur à la liste des fournisseurs disponibles
+func RegisterOIDCProvider(cfg OIDCProviderConfig) error {
+	if cfg.Name == "" || cfg.IssuerURL == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return fmt.Errorf("fournisseur OIDC %q incomplet : nom, issuer, client ID et URL de redirection requis", cfg.Name)
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{oidc.ScopeOpenID, "email", "profile"}
+	}
+
+	OIDCProviders[cfg.Name] = &OIDCProvider{Config: cfg}
+	return nil
+}
+
+// setup effectue la découverte du fournisseur si elle n'a pas encore réussi
+func (p *OIDCProvider) setup(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth2 != nil {
+		return nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, p.Config.IssuerURL)
+	if err != nil {
+		return fmt.Errorf("découverte du fournisseur OIDC %q : %w", p.Config.Name, err)
+	}
+
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.Config.ClientID})
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.Config.ClientID,
+		ClientSecret: p.Config.ClientSecret,
+		RedirectURL:  p.Config.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.Config.Scopes,
+	}
+	return nil
+}
+
+// AuthCodeURL construit l'URL d'autorisation (flux authorization code + PKCE S256)
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	if err := p.setup(ctx); err != nil {
+		return "", err
+	}
+	return p.oauth2.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// OIDCClaims regroupe les claims de l'ID token utilisés par l'API
+type OIDCClaims struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+}
+
+var ErrOIDCNonceMismatch = errors.New("nonce de l'ID token invalide")
+
+// Exchange échange le code d'autorisation contre des jetons, puis vérifie l'ID token (signature, issuer, audience, nonce)
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCClaims, error) {
+	if err := p.setup(ctx); err != nil {
+		return nil, err
+	}
+
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("échange du code d'autorisation : %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("réponse du fournisseur sans id_token")
+	}
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("vérification de l'ID token : %w", err)
+	}
+
+	var claims OIDCClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	if claims.Nonce != nonce {
+		return nil, ErrOIDCNonceMismatch
+	}
+	return &claims, nil
+}
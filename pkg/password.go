@@ -0,0 +1,168 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithmes de hachage des mots de passe supportés
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+// PasswordHashingConfig regroupe les paramètres de hachage des mots de passe
+type PasswordHashingConfig struct {
+	Algorithm     string // algorithme utilisé pour les nouveaux hash
+	BcryptCost    int
+	Argon2Memory  uint32 // mémoire utilisée, en KiB
+	Argon2Time    uint32 // nombre d'itérations
+	Argon2Threads uint8
+	Argon2SaltLen uint32
+	Argon2KeyLen  uint32
+}
+
+// PasswordHashing contient les paramètres courants. argon2id est l'algorithme privilégié,
+// les anciens hash bcrypt restent acceptés et sont recalculés à la connexion
+var PasswordHashing = PasswordHashingConfig{
+	Algorithm:     HashArgon2id,
+	BcryptCost:    bcrypt.DefaultCost,
+	Argon2Memory:  64 * 1024,
+	Argon2Time:    3,
+	Argon2Threads: 2,
+	Argon2SaltLen: 16,
+	Argon2KeyLen:  32,
+}
+
+// BcryptMaxPasswordBytes est la longueur maximale, en octets, d'un mot de passe haché avec bcrypt
+const BcryptMaxPasswordBytes = 72
+
+var errInvalidHash = errors.New("format de hash de mot de passe invalide")
+
+// Validate vérifie la cohérence des paramètres de hachage
+func (cfg PasswordHashingConfig) Validate() error {
+	switch cfg.Algorithm {
+	case HashArgon2id, HashBcrypt:
+	default:
+		return fmt.Errorf("algorithme de hachage inconnu : %q", cfg.Algorithm)
+	}
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("coût bcrypt invalide : %d (attendu entre %d et %d)", cfg.BcryptCost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if cfg.Argon2Memory < 8*uint32(cfg.Argon2Threads) || cfg.Argon2Time < 1 || cfg.Argon2Threads < 1 {
+		return errors.New("paramètres argon2id invalides")
+	}
+	if cfg.Argon2SaltLen < 8 || cfg.Argon2KeyLen < 16 {
+		return errors.New("longueurs de sel ou de clé argon2id trop courtes")
+	}
+	return nil
+}
+
+// HashPassword hache un mot de passe avec l'algorithme et les paramètres courants
+func HashPassword(password string) (string, error) {
+	cfg := PasswordHashing
+
+	if cfg.Algorithm == HashBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, cfg.Argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, cfg.Argon2Time, cfg.Argon2Memory, cfg.Argon2Threads, cfg.Argon2KeyLen)
+
+	// Format PHC : $argon2id$v=19$m=65536,t=3,p=2$<sel>$<hash>
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, cfg.Argon2Memory, cfg.Argon2Time, cfg.Argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword compare un mot de passe à son hash (argon2id ou bcrypt).
+// needsRehash indique que le hash n'utilise pas l'algorithme ou les paramètres courants
+func VerifyPassword(hash, password string) (ok bool, needsRehash bool, err error) {
+	cfg := PasswordHashing
+
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := decodeArgon2idHash(hash)
+		if err != nil {
+			return false, false, err
+		}
+		computed := argon2.IDKey([]byte(password), salt, params.Argon2Time, params.Argon2Memory, params.Argon2Threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, false, nil
+		}
+		needsRehash = cfg.Algorithm != HashArgon2id ||
+			params.Argon2Memory != cfg.Argon2Memory ||
+			params.Argon2Time != cfg.Argon2Time ||
+			params.Argon2Threads != cfg.Argon2Threads ||
+			uint32(len(salt)) != cfg.Argon2SaltLen ||
+			uint32(len(key)) != cfg.Argon2KeyLen
+		return true, needsRehash, nil
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, false, err
+	}
+	return true, cfg.Algorithm != HashBcrypt || cost != cfg.BcryptCost, nil
+}
+
+// decodeArgon2idHash extrait les paramètres, le sel et la clé d'un hash argon2id au format PHC
+func decodeArgon2idHash(hash string) (PasswordHashingConfig, []byte, []byte, error) {
+	var params PasswordHashingConfig
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != HashArgon2id {
+		return params, nil, nil, errInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Time, &params.Argon2Threads); err != nil {
+		return params, nil, nil, errInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidHash
+	}
+
+	params.Algorithm = HashArgon2id
+	return params, salt, key, nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// VerifyDummyPassword effectue une vérification factice au coût équivalent, pour ne pas révéler
+// par le temps de réponse qu'un compte n'existe pas
+func VerifyDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashPassword(GenerateToken())
+	})
+	VerifyPassword(dummyHash, password)
+}
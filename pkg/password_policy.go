@@ -0,0 +1,407 @@
+package pkg
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PasswordIssue décrit une raison de rejet d'un mot de passe
+type PasswordIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Codes des raisons de rejet d'un mot de passe
+const (
+	PasswordTooShort         = "too_short"
+	PasswordMissingClasses   = "missing_character_classes"
+	PasswordCommon           = "common_password"
+	PasswordBreached         = "breached_password"
+	PasswordContainsUserData = "contains_user_data"
+	PasswordTooWeak          = "too_weak"
+	PasswordTooLong          = "too_long"
+)
+
+// MaxPasswordLength est la longueur maximale, en caractères, d'un nouveau mot de passe : au-delà, la requête est
+// refusée avant toute analyse
+const MaxPasswordLength = 128
+
+// Longueur maximale d'un mot recherché dans le mot de passe par PasswordStrength, qui borne le coût de la recherche.
+// Un mot de passe plus long que ce mot est de toute façon comparé tel quel aux listes par CheckPassword
+const maxKnownWordLength = 32
+
+// PasswordMinStrength est le score minimal (de 0 à 4, à la manière de zxcvbn) exigé pour un mot de passe
+var PasswordMinStrength = 2
+
+// Liste intégrée des mots de passe les plus courants, toujours appliquée
+//
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = loadWordList(strings.NewReader(commonPasswordsFile))
+
+// PasswordBlocklist est la liste locale configurée par password.blocklist_path (nil si aucune)
+var PasswordBlocklist *Blocklist
+
+// Blocklist est une liste locale de mots de passe interdits. Trois formats sont reconnus :
+//   - un fichier de mots de passe en clair, un par ligne (ex. top 100k) ;
+//   - un fichier d'empreintes SHA-1 en hexadécimal, au format "EMPREINTE" ou "EMPREINTE:occurrences" ;
+//   - un répertoire de fichiers de plages k-anonymity nommés d'après les 5 premiers caractères
+//     de l'empreinte SHA-1 et contenant des lignes "SUFFIXE:occurrences".
+type Blocklist struct {
+	words  map[string]struct{}
+	hashes map[string]struct{}
+	dir    string
+}
+
+// loadWordList lit une liste de mots, un par ligne, en ignorant la casse
+func loadWordList(reader io.Reader) map[string]struct{} {
+	words := make(map[string]struct{})
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if word := strings.ToLower(strings.TrimSpace(scanner.Text())); word != "" {
+			words[word] = struct{}{}
+		}
+	}
+	return words
+}
+
+var sha1LineRegex = regexp.MustCompile(`^[0-9A-Fa-f]{40}(:\d+)?$`)
+
+// LoadPasswordBlocklist charge une liste de mots de passe interdits depuis un fichier ou un répertoire
+func LoadPasswordBlocklist(path string) (*Blocklist, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &Blocklist{dir: path}, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blocklist := &Blocklist{words: make(map[string]struct{}), hashes: make(map[string]struct{})}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if sha1LineRegex.MatchString(line) {
+			blocklist.hashes[strings.ToUpper(line[:40])] = struct{}{}
+		} else {
+			blocklist.words[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return blocklist, nil
+}
+
+// Contains indique si le mot de passe figure dans la liste
+func (b *Blocklist) Contains(password string) bool {
+	if _, found := b.words[strings.ToLower(password)]; found {
+		return true
+	}
+
+	sum := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(sum[:]))
+	if _, found := b.hashes[digest]; found {
+		return true
+	}
+
+	if b.dir != "" {
+		return rangeFileContains(b.dir, digest)
+	}
+	return false
+}
+
+// rangeFileContains cherche le suffixe de l'empreinte dans le fichier de plage correspondant à son préfixe
+func rangeFileContains(dir, digest string) bool {
+	prefix, suffix := digest[:5], digest[5:]
+
+	for _, name := range []string{prefix, prefix + ".txt", strings.ToLower(prefix), strings.ToLower(prefix) + ".txt"} {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+			if strings.EqualFold(line, suffix) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// CheckPassword vérifie un mot de passe et renvoie toutes les raisons de rejet (aucune s'il est accepté).
+// userInputs contient les données propres à l'utilisateur (username, email) que le mot de passe ne doit pas reprendre
+func CheckPassword(password string, userInputs ...string) []PasswordIssue {
+	var issues []PasswordIssue
+
+	if len([]rune(password)) < 8 {
+		issues = append(issues, PasswordIssue{PasswordTooShort, "Le mot de passe doit contenir au moins 8 caractères"})
+	}
+	if !ValidatePassword(password) {
+		issues = append(issues, PasswordIssue{PasswordMissingClasses, "Le mot de passe doit contenir une majuscule, une minuscule et un chiffre"})
+	}
+	//bcrypt refuse les mots de passe de plus de 72 octets, soit moins de 128 caractères hors ASCII
+	if PasswordHashing.Algorithm == HashBcrypt && len(password) > BcryptMaxPasswordBytes {
+		issues = append(issues, PasswordIssue{PasswordTooLong, "Le mot de passe ne doit pas dépasser 72 octets"})
+	}
+
+	if _, found := commonPasswords[strings.ToLower(password)]; found {
+		issues = append(issues, PasswordIssue{PasswordCommon, "Ce mot de passe fait partie des plus courants"})
+	} else if PasswordBlocklist != nil && PasswordBlocklist.Contains(password) {
+		issues = append(issues, PasswordIssue{PasswordBreached, "Ce mot de passe figure dans une liste de mots de passe compromis"})
+	}
+
+	related := relatedWords(userInputs)
+	lowered := strings.ToLower(password)
+	for word := range related {
+		if strings.Contains(lowered, word) {
+			issues = append(issues, PasswordIssue{PasswordContainsUserData, "Le mot de passe ne doit pas contenir le username ou l'email"})
+			break
+		}
+	}
+
+	if PasswordStrength(password, userInputs...) < PasswordMinStrength {
+		issues = append(issues, PasswordIssue{PasswordTooWeak, "Le mot de passe est trop facile à deviner"})
+	}
+
+	return issues
+}
+
+// relatedWords extrait les mots propres à l'utilisateur : username, email et partie locale de l'email
+func relatedWords(userInputs []string) map[string]struct{} {
+	words := make(map[string]struct{})
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		candidates := []string{input}
+		if local, _, found := strings.Cut(input, "@"); found {
+			candidates = append(candidates, local)
+		}
+		for _, candidate := range candidates {
+			if len(candidate) >= 3 {
+				words[candidate] = struct{}{}
+			}
+		}
+	}
+	return words
+}
+
+// Substitutions courantes ("l33t speak") annulées avant la recherche de mots connus
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i")
+
+// Rangées de clavier (QWERTY et AZERTY) reconnues comme séquences
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm", "azertyuiop", "qsdfghjklm", "wxcvbn"}
+
+// PasswordStrength estime la robustesse d'un mot de passe sur une échelle de 0 à 4, à la manière de zxcvbn :
+// le mot de passe est découpé en motifs devinables (mots courants, données de l'utilisateur, séquences,
+// répétitions, années) dont le coût est faible, le reste étant compté comme des caractères aléatoires
+func PasswordStrength(password string, userInputs ...string) int {
+	runes := []rune(password)
+	lowered := []rune(strings.ToLower(password))
+	unleeted := []rune(leetReplacer.Replace(strings.ToLower(password)))
+	if len(unleeted) != len(runes) {
+		unleeted = lowered
+	}
+
+	dictionary := relatedWords(userInputs)
+	covered := make([]bool, len(runes))
+	bits := 0.0
+
+	// Mots connus, du plus long au plus court
+	for length := min(len(runes), maxKnownWordLength); length >= 4; length-- {
+		for start := 0; start+length <= len(runes); start++ {
+			if isCovered(covered, start, length) {
+				continue
+			}
+			candidate := string(unleeted[start : start+length])
+			plain := string(lowered[start : start+length])
+			if isKnownWord(candidate, dictionary) || isKnownWord(plain, dictionary) {
+				markCovered(covered, start, length)
+				bits += 10 // environ un millier de candidats
+				if string(runes[start:start+length]) != plain {
+					bits++ // majuscules
+				}
+			}
+		}
+	}
+
+	// Séquences, rangées de clavier et répétitions
+	for start := 0; start < len(lowered); {
+		length := patternLength(lowered[start:])
+		if length >= 3 && !isCovered(covered, start, length) {
+			markCovered(covered, start, length)
+			bits += 4 + math.Log2(float64(length))
+			start += length
+			continue
+		}
+		start++
+	}
+
+	// Années (1900-2099)
+	for start := 0; start+4 <= len(lowered); start++ {
+		chunk := string(lowered[start : start+4])
+		if (strings.HasPrefix(chunk, "19") || strings.HasPrefix(chunk, "20")) && isDigits(chunk) && !isCovered(covered, start, 4) {
+			markCovered(covered, start, 4)
+			bits += math.Log2(200)
+		}
+	}
+
+	// Caractères restants, supposés aléatoires
+	pool := charsetSize(runes)
+	for i := range runes {
+		if !covered[i] {
+			bits += math.Log2(float64(pool))
+		}
+	}
+
+	switch {
+	case bits < math.Log2(1e3):
+		return 0
+	case bits < math.Log2(1e6):
+		return 1
+	case bits < math.Log2(1e8):
+		return 2
+	case bits < math.Log2(1e10):
+		return 3
+	default:
+		return 4
+	}
+}
+
+// patternLength renvoie la longueur de la séquence (abc, 321, azerty...) ou répétition (aaa) en tête de s
+func patternLength(s []rune) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+
+	// Répétition du même caractère ou suite de codes consécutifs
+	step := s[1] - s[0]
+	if step >= -1 && step <= 1 {
+		length := 2
+		for length < len(s) && s[length]-s[length-1] == step {
+			length++
+		}
+		if length >= 3 {
+			return length
+		}
+	}
+
+	// Rangées de clavier, dans un sens ou dans l'autre
+	best := 1
+	for _, row := range keyboardRows {
+		for _, candidate := range []string{row, reverse(row)} {
+			length := 0
+			for length < len(s) && strings.Contains(candidate, string(s[:length+1])) {
+				length++
+			}
+			if length > best {
+				best = length
+			}
+		}
+	}
+	return best
+}
+
+// charsetSize estime la taille de l'alphabet utilisé par le mot de passe
+func charsetSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	size := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			size += class.size
+		}
+	}
+	if size == 0 {
+		return 1
+	}
+	return size
+}
+
+// isKnownWord indique si le mot figure parmi les mots de passe courants, la liste configurée ou les données de l'utilisateur
+func isKnownWord(word string, dictionary map[string]struct{}) bool {
+	if _, found := commonPasswords[word]; found {
+		return true
+	}
+	if _, found := dictionary[word]; found {
+		return true
+	}
+	if PasswordBlocklist != nil {
+		if _, found := PasswordBlocklist.words[word]; found {
+			return true
+		}
+	}
+	return false
+}
+
+func isCovered(covered []bool, start, length int) bool {
+	for i := start; i < start+length; i++ {
+		if covered[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func markCovered(covered []bool, start, length int) {
+	for i := start; i < start+length; i++ {
+		covered[i] = true
+	}
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
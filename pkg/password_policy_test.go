@@ -0,0 +1,274 @@
+package pkg
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// issueCodes renvoie les codes des raisons de rejet
+func issueCodes(issues []PasswordIssue) []string {
+	codes := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func hasIssue(issues []PasswordIssue, code string) bool {
+	for _, issue := range issues {
+		if issue.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// withBlocklist remplace la liste configurée le temps du test
+func withBlocklist(t *testing.T, blocklist *Blocklist) {
+	previous := PasswordBlocklist
+	PasswordBlocklist = blocklist
+	t.Cleanup(func() { PasswordBlocklist = previous })
+}
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPasswordStrength(t *testing.T) {
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"", 0},
+		{"a", 0},
+		{"aaaaaaaaaaaaaaaa", 0}, // répétition
+		{"abcdefgh", 1},         // séquence
+		{"azertyuiop", 1},       // rangée de clavier
+		{"password", 1},         // mot courant
+		{"P@ssw0rd", 1},         // mot courant en l33t
+		{"Soleil2024", 1},       // mot courant et année
+		// Chaque caractère aléatoire ajouté fait franchir un seuil
+		{"Soleil2024k", 2},
+		{"Soleil2024kq", 3},
+		{"Soleil2024kqz", 4},
+		{"kQ8#vLp2", 4},
+		{"Tr0ub4dor&3xyz!Q", 4},
+	}
+	for _, tt := range tests {
+		if got := PasswordStrength(tt.password); got != tt.want {
+			t.Errorf("PasswordStrength(%q) = %d, %d attendu", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestPasswordStrengthUserInputs(t *testing.T) {
+	//Un username inconnu des listes ne compte comme mot connu que pour son titulaire
+	if got := PasswordStrength("Zorglub2024k"); got != 4 {
+		t.Errorf("sans données utilisateur : %d, 4 attendu", got)
+	}
+	if got := PasswordStrength("Zorglub2024k", "zorglub", "zorglub@example.com"); got != 2 {
+		t.Errorf("avec le username : %d, 2 attendu", got)
+	}
+}
+
+func TestPasswordStrengthBounds(t *testing.T) {
+	for _, password := range []string{"", "x", strings.Repeat("a", MaxPasswordLength), strings.Repeat("Zx9!", MaxPasswordLength/4), strings.Repeat("é", 500)} {
+		if got := PasswordStrength(password); got < 0 || got > 4 {
+			t.Errorf("PasswordStrength(%d caractères) = %d, hors de 0-4", len([]rune(password)), got)
+		}
+	}
+}
+
+// La recherche de mots connus est bornée à maxKnownWordLength : un mot de passe de longueur maximale reste rapide à
+// évaluer, et un mot courant y est toujours reconnu
+func TestPasswordStrengthLongPassword(t *testing.T) {
+	random := "kQ8#vLp2Zx9!mW3@"
+	long := strings.Repeat(random, MaxPasswordLength/len(random))
+	start := time.Now()
+	if got := PasswordStrength(long); got != 4 {
+		t.Errorf("PasswordStrength(%d caractères) = %d, 4 attendu", len(long), got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("PasswordStrength(%d caractères) : %v", len(long), elapsed)
+	}
+
+	if got, alone := PasswordStrength("password"+strings.Repeat("a", 120)), PasswordStrength(strings.Repeat("a", 120)); got > alone+1 {
+		t.Errorf("mot courant dans un mot de passe long : %d, au plus %d attendu", got, alone+1)
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	withBlocklist(t, nil)
+	tests := []struct {
+		password string
+		want     []string
+	}{
+		{"Tr0ub4dor&3xyz!Q", []string{}},
+		{"kQ8#vLp2", []string{}},                                        // 8 caractères : longueur minimale
+		{"kQ8#vLp", []string{PasswordTooShort, PasswordMissingClasses}}, // la règle des classes exige aussi 8 caractères
+		{"kq8#vlp2x", []string{PasswordMissingClasses}},
+		{"Password1", []string{PasswordCommon, PasswordTooWeak}},
+		{"Azerty123", []string{PasswordCommon, PasswordTooWeak}}, // casse ignorée
+		{"Alice2026kqz", []string{PasswordContainsUserData}},
+		{"Soleil2024", []string{PasswordTooWeak}},
+	}
+	for _, tt := range tests {
+		got := issueCodes(CheckPassword(tt.password, "alice", "alice@example.com"))
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("CheckPassword(%q) = %v, %v attendu", tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPasswordMaxLength(t *testing.T) {
+	withBlocklist(t, nil)
+	password := strings.Repeat("kQ8#vLp2", MaxPasswordLength/8)
+	if len([]rune(password)) != MaxPasswordLength {
+		t.Fatalf("longueur %d", len([]rune(password)))
+	}
+	if issues := CheckPassword(password, "alice"); len(issues) != 0 {
+		t.Errorf("mot de passe de %d caractères refusé : %v", MaxPasswordLength, issueCodes(issues))
+	}
+}
+
+// bcrypt ne hache que 72 octets : la limite porte sur les octets, pas sur les caractères
+func TestCheckPasswordBcryptMaxBytes(t *testing.T) {
+	withBlocklist(t, nil)
+	previous := PasswordHashing
+	t.Cleanup(func() { PasswordHashing = previous })
+
+	ascii := strings.Repeat("kQ8#vLp2", 9)               // 72 octets
+	accented := strings.Repeat("Noël-Été-Élève-Ça-1", 4) // 76 caractères, 100 octets
+	for _, algorithm := range []string{HashArgon2id, HashBcrypt} {
+		PasswordHashing.Algorithm = algorithm
+		if issues := CheckPassword(ascii); len(issues) != 0 {
+			t.Errorf("%s, 72 octets : %v", algorithm, issueCodes(issues))
+		}
+		want := []string{}
+		if algorithm == HashBcrypt {
+			want = []string{PasswordTooLong}
+		}
+		if got := issueCodes(CheckPassword(accented)); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s, %d octets : %v, %v attendu", algorithm, len(accented), got, want)
+		}
+	}
+
+	//Le mot de passe accepté est haché sans erreur
+	PasswordHashing.Algorithm, PasswordHashing.BcryptCost = HashBcrypt, 4
+	if _, err := HashPassword(ascii); err != nil {
+		t.Errorf("HashPassword(72 octets) : %v", err)
+	}
+}
+
+func TestCommonPasswordsEmbedded(t *testing.T) {
+	if len(commonPasswords) < 100 {
+		t.Fatalf("%d mots de passe courants chargés", len(commonPasswords))
+	}
+	for _, password := range []string{"123456", "password", "azerty", "motdepasse", "Soleil"} {
+		if _, found := commonPasswords[strings.ToLower(password)]; !found {
+			t.Errorf("%q absent de la liste intégrée", password)
+		}
+	}
+}
+
+func TestBlocklistPlainText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "top.txt")
+	writeFile(t, path, "# mots de passe compromis\nHunter2Hunter\n\n  Correct9Horse  \n")
+	blocklist, err := LoadPasswordBlocklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for password, want := range map[string]bool{
+		"Hunter2Hunter":             true,
+		"hunter2hunter":             true,  // casse ignorée
+		"Correct9Horse":             true,  // espaces de la ligne ignorés
+		"# mots de passe compromis": false, // commentaire
+		"Tr0ub4dor&3xyz!Q":          false,
+		"":                          false,
+	} {
+		if got := blocklist.Contains(password); got != want {
+			t.Errorf("Contains(%q) = %v, %v attendu", password, got, want)
+		}
+	}
+
+	withBlocklist(t, blocklist)
+	if issues := CheckPassword("Hunter2Hunter"); !hasIssue(issues, PasswordBreached) {
+		t.Errorf("CheckPassword : %v, %s attendu", issueCodes(issues), PasswordBreached)
+	}
+}
+
+func TestBlocklistSHA1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	writeFile(t, path, sha1Hex("Hunter2Hunter")+"\n"+strings.ToLower(sha1Hex("Correct9Horse"))+":42\n")
+	blocklist, err := LoadPasswordBlocklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for password, want := range map[string]bool{
+		"Hunter2Hunter": true,
+		"Correct9Horse": true, // empreinte en minuscules, avec occurrences
+		"hunter2hunter": false,
+		"Tr0ub4dor&3xy": false,
+	} {
+		if got := blocklist.Contains(password); got != want {
+			t.Errorf("Contains(%q) = %v, %v attendu", password, got, want)
+		}
+	}
+	if blocklist.Contains(sha1Hex("Hunter2Hunter")) {
+		t.Error("une empreinte ne doit pas être reconnue comme mot de passe en clair")
+	}
+}
+
+func TestBlocklistRangeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for _, password := range []string{"Hunter2Hunter", "Correct9Horse"} {
+		digest := sha1Hex(password)
+		name := digest[:5]
+		if password == "Correct9Horse" {
+			name = strings.ToLower(name) + ".txt" // les deux conventions de nommage sont acceptées
+		}
+		writeFile(t, filepath.Join(dir, name), "0000000000000000000000000000000000A:1\n"+digest[5:]+":1337\n")
+	}
+	blocklist, err := LoadPasswordBlocklist(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for password, want := range map[string]bool{
+		"Hunter2Hunter": true,
+		"Correct9Horse": true,
+		"Tr0ub4dor&3xy": false, // aucun fichier pour ce préfixe
+	} {
+		if got := blocklist.Contains(password); got != want {
+			t.Errorf("Contains(%q) = %v, %v attendu", password, got, want)
+		}
+	}
+
+	//Même préfixe, suffixe absent
+	digest := sha1Hex("Hunter2Hunter")
+	writeFile(t, filepath.Join(dir, digest[:5]), "0000000000000000000000000000000000A:1\n")
+	if blocklist.Contains("Hunter2Hunter") {
+		t.Error("suffixe absent du fichier de plage reconnu")
+	}
+}
+
+func TestLoadPasswordBlocklistMissing(t *testing.T) {
+	if _, err := LoadPasswordBlocklist(filepath.Join(t.TempDir(), "absent.txt")); err == nil {
+		t.Error("erreur attendue pour un fichier absent")
+	}
+}
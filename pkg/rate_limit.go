@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit décrit une politique de seau de jetons : Requests requêtes par Period, avec des rafales
+// jusqu'à Burst requêtes (Requests par défaut)
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Capacity renvoie la taille du seau
+func (l RateLimit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// refillRate renvoie le nombre de jetons regagnés par seconde
+func (l RateLimit) refillRate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// RateLimitResult est le résultat de la consommation d'un jeton
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // jetons restants après la requête
+	ResetAfter time.Duration // délai avant que le seau soit de nouveau plein
+	RetryAfter time.Duration // délai avant le prochain jeton disponible, si la requête est refusée
+}
+
+// RateLimitStore conserve l'état des seaux de jetons. MemoryRateLimitStore convient à une instance unique ;
+// une implémentation partagée (Redis, base de données...) permet d'appliquer les limites à plusieurs instances
+type RateLimitStore interface {
+	// Take consomme un jeton du seau identifié par key
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// DefaultRateLimitStore est le stockage utilisé par les politiques de limitation définies dans SetupRouter
+var DefaultRateLimitStore RateLimitStore = NewMemoryRateLimitStore()
+
+// MemoryRateLimitStore conserve les seaux de jetons en mémoire
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens   float64
+	updated  time.Time
+	capacity float64
+	rate     float64
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := TimeNow()
+	s.sweep(now)
+
+	capacity := float64(limit.Capacity())
+	rate := limit.refillRate()
+
+	bucket, exists := s.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		s.buckets[key] = bucket
+	}
+	bucket.capacity, bucket.rate = capacity, rate
+
+	//Remplir le seau selon le temps écoulé
+	elapsed := now.Sub(bucket.updated).Seconds()
+	bucket.tokens = math.Min(capacity, bucket.tokens+elapsed*rate)
+	bucket.updated = now
+
+	result := RateLimitResult{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(bucket.tokens))
+	result.ResetAfter = secondsToDuration((capacity - bucket.tokens) / rate)
+
+	return result, nil
+}
+
+// sweep supprime les seaux redevenus pleins, qui n'apportent plus d'information
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*bucket.rate >= bucket.capacity {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock remplace TimeNow le temps du test ; advance fait avancer l'horloge
+func fakeClock(t *testing.T) (advance func(time.Duration)) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	previous := TimeNow
+	TimeNow = func() time.Time { return now }
+	t.Cleanup(func() { TimeNow = previous })
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+// take consomme un jeton et échoue si le résultat diffère de celui attendu
+func take(t *testing.T, store *MemoryRateLimitStore, key string, limit RateLimit, want RateLimitResult) {
+	t.Helper()
+	got, err := store.Take(context.Background(), key, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//Arrondi à la milliseconde : les délais sont calculés en virgule flottante
+	got.ResetAfter, got.RetryAfter = got.ResetAfter.Round(time.Millisecond), got.RetryAfter.Round(time.Millisecond)
+	if got != want {
+		t.Fatalf("Take(%q) = %+v, %+v attendu", key, got, want)
+	}
+}
+
+func TestRateLimitCapacity(t *testing.T) {
+	if got := (RateLimit{Requests: 20, Period: time.Minute}).Capacity(); got != 20 {
+		t.Errorf("Capacity sans rafale = %d, 20 attendu", got)
+	}
+	if got := (RateLimit{Requests: 30, Period: time.Minute, Burst: 10}).Capacity(); got != 10 {
+		t.Errorf("Capacity avec rafale = %d, 10 attendu", got)
+	}
+}
+
+func TestMemoryRateLimitStoreBurst(t *testing.T) {
+	fakeClock(t)
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Requests: 3, Period: time.Minute} // un jeton toutes les 20 secondes
+
+	take(t, store, "k", limit, RateLimitResult{Allowed: true, Remaining: 2, ResetAfter: 20 * time.Second})
+	take(t, store, "k", limit, RateLimitResult{Allowed: true, Remaining: 1, ResetAfter: 40 * time.Second})
+	take(t, store, "k", limit, RateLimitResult{Allowed: true, Remaining: 0, ResetAfter: time.Minute})
+	//Seau vide : refus, le prochain jeton arrive dans 20 secondes et le seau est plein dans une minute
+	take(t, store, "k", limit, RateLimitResult{Allowed: false, Remaining: 0, ResetAfter: time.Minute, RetryAfter: 20 * time.Second})
+	take(t, store, "k", limit, RateLimitResult{Allowed: false, Remaining: 0, ResetAfter: time.Minute, RetryAfter: 20 * time.Second})
+}
+
+func TestMemoryRateLimitStoreRefill(t *testing.T) {
+	advance := fakeClock(t)
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Requests: 3, Period: time.Minute}
+	for i := 1; i <= 3; i++ {
+		take(t, store, "k", limit, RateLimitResult{Allowed: true, Remaining: 3 - i, ResetAfter: time.Duration(i) * 20 * time.Second})
+	}
+
+	//Un demi-jeton regagné : toujours refusé, le délai d'attente diminue d'autant
+	advance(10 * time.Second)
+	take(t, store, "k", limit, RateLimitResult{Allowed: false, Remaining: 0, ResetAfter: 50 * time.Second, RetryAfter: 10 * time.Second})
+
+	//Un jeton entier : accepté
+	advance(10 * time.Second)
+	take(t, store, "k", limit, RateLimitResult{Allowed: true, Remaining: 0, ResetAfter: time.Minute})
+
+	//Le seau ne dépasse pas sa capacité, même après une longue inactivité
+	advance(time.Hour)
+	take(t, store, "k", limit, RateLimitResult{Allowed: true, Remaining: 2, ResetAfter: 20 * time.Second})
+}
+
+func TestMemoryRateLimitStoreBurstAboveRate(t *testing.T) {
+	advance := fakeClock(t)
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Requests: 60, Period: time.Minute, Burst: 5} // un jeton par seconde, rafales de 5
+
+	for i := 4; i >= 0; i-- {
+		take(t, store, "k", limit, RateLimitResult{Allowed: true, Remaining: i, ResetAfter: time.Duration(5-i) * time.Second})
+	}
+	take(t, store, "k", limit, RateLimitResult{Allowed: false, Remaining: 0, ResetAfter: 5 * time.Second, RetryAfter: time.Second})
+
+	advance(2500 * time.Millisecond)
+	take(t, store, "k", limit, RateLimitResult{Allowed: true, Remaining: 1, ResetAfter: 3500 * time.Millisecond})
+}
+
+func TestMemoryRateLimitStoreKeys(t *testing.T) {
+	fakeClock(t)
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Requests: 1, Period: time.Minute}
+
+	take(t, store, "a", limit, RateLimitResult{Allowed: true, Remaining: 0, ResetAfter: time.Minute})
+	take(t, store, "a", limit, RateLimitResult{Allowed: false, Remaining: 0, ResetAfter: time.Minute, RetryAfter: time.Minute})
+	//Chaque clé a son propre seau
+	take(t, store, "b", limit, RateLimitResult{Allowed: true, Remaining: 0, ResetAfter: time.Minute})
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	advance := fakeClock(t)
+	store := NewMemoryRateLimitStore()
+	limit := RateLimit{Requests: 2, Period: 2 * time.Minute} // un jeton par minute
+
+	take(t, store, "a", limit, RateLimitResult{Allowed: true, Remaining: 1, ResetAfter: time.Minute})
+	take(t, store, "b", limit, RateLimitResult{Allowed: true, Remaining: 1, ResetAfter: time.Minute})
+	take(t, store, "b", limit, RateLimitResult{Allowed: true, Remaining: 0, ResetAfter: 2 * time.Minute})
+
+	//Le nettoyage n'a lieu qu'une fois par minute
+	advance(40 * time.Second)
+	take(t, store, "c", limit, RateLimitResult{Allowed: true, Remaining: 1, ResetAfter: time.Minute})
+	if len(store.buckets) != 3 {
+		t.Fatalf("%d seaux avant le nettoyage, 3 attendus", len(store.buckets))
+	}
+	//Après 70 secondes, a est de nouveau plein, b et c ne le sont pas
+	advance(30 * time.Second)
+	take(t, store, "d", limit, RateLimitResult{Allowed: true, Remaining: 1, ResetAfter: time.Minute})
+	if _, found := store.buckets["a"]; found {
+		t.Error("seau plein conservé après le nettoyage")
+	}
+	for _, key := range []string{"b", "c", "d"} {
+		if _, found := store.buckets[key]; !found {
+			t.Errorf("seau %s entamé supprimé par le nettoyage", key)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package pkg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+)
+
+// RequestIDHeader est l'en-tête qui transporte l'identifiant de requête, reçu d'un proxy ou généré par l'API
+const RequestIDHeader = "X-Request-ID"
+
+// Identifiants acceptés depuis le client : courts et sans caractère susceptible de corrompre les logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type requestIDKey struct{}
+
+// NewRequestID génère un identifiant de requête aléatoire
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID indique si un identifiant reçu du client peut être réutilisé
+func ValidRequestID(id string) bool {
+	return validRequestID.MatchString(id)
+}
+
+// WithRequestID renvoie un contexte portant l'identifiant de requête
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext renvoie l'identifiant de requête du contexte, ou une chaîne vide
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
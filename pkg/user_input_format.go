@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"regexp"
+	"strings"
 	"github.com/dlclark/regexp2"
 )
 
@@ -14,6 +15,12 @@ func ValidateEmailFormat(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
+// NormalizeEmail met l'email sous sa forme canonique (minuscules, sans espaces autour), afin que l'unicité
+// et les recherches ne dépendent pas de la collation du moteur de base de données
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 func ValidateUsernameFormat(username string) bool {
 	return usernameRegex.MatchString(username)
 }
@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+// Informations de build, injectées à la compilation :
+//
+//	go build -ldflags "-X to-do-list-api/pkg.Version=1.2.0 -X to-do-list-api/pkg.Commit=$(git rev-parse HEAD) \
+//	  -X to-do-list-api/pkg.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" -o todo-api ./cmd
+//
+// À défaut, le commit et la date sont lus dans les informations VCS enregistrées par go build
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// BuildInfo décrit le binaire en cours d'exécution (réponse de /version)
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	Modified  bool   `json:"modified,omitempty"` // compilé depuis un arbre de travail modifié
+}
+
+// GetBuildInfo renvoie les informations de build
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{Version: Version, Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = "unknown"
+	}
+	return info
+}
+
+// draining est vrai dès que l'arrêt du serveur a commencé
+var draining atomic.Bool
+
+// SetDraining signale que le serveur s'arrête : la sonde de disponibilité échoue alors
+// pour que l'orchestrateur cesse de lui envoyer du trafic
+func SetDraining() {
+	draining.Store(true)
+}
+
+// IsDraining indique si l'arrêt du serveur a commencé
+func IsDraining() bool {
+	return draining.Load()
+}
@@ -0,0 +1,365 @@
+package routes_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"to-do-list-api/apierror"
+	"to-do-list-api/config"
+	"to-do-list-api/pkg"
+	"to-do-list-api/routes"
+	"to-do-list-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contractStep est une requête du parcours, avec le statut attendu
+type contractStep struct {
+	method string
+	path   string
+	body   string // JSON, ou formulaire si form est vrai
+	form   bool
+	as     string // compte dont le jeton de session accompagne la requête
+	status int
+}
+
+// contractRun exécute le parcours et signale chaque écart au test
+type contractRun struct {
+	t        *testing.T
+	contract *apiContract
+	router   *gin.Engine
+	tokens   map[string]string
+	mails    *capturedMails
+	checks   int
+}
+
+// TestContract confronte le document OpenAPI (openapi/openapi.yaml) à l'API réelle, servie en mémoire :
+//   - chaque route du routeur est décrite, et chaque opération décrite est routée ;
+//   - les codes d'erreur du document sont ceux du catalogue ;
+//   - les réponses d'un parcours complet de l'API (succès et erreurs) respectent le document.
+//
+// go test -v affiche chaque requête vérifiée
+func TestContract(t *testing.T) {
+	contract, err := loadContract()
+	if err != nil {
+		t.Fatalf("document OpenAPI invalide : %v", err)
+	}
+
+	//Configuration par défaut, dépôts en mémoire ; les journaux et les emails sont interceptés
+	cfg := config.Default()
+	cfg.Database.URL = "memory://"
+	if err := cfg.Apply(); err != nil {
+		t.Fatalf("configuration invalide : %v", err)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	gin.SetMode(gin.ReleaseMode)
+	mails := &capturedMails{}
+	pkg.DefaultMailer = mails
+
+	s := store.NewMemoryStore()
+	run := &contractRun{
+		t:        t,
+		contract: contract,
+		router:   routes.SetupRouter(s),
+		tokens:   map[string]string{},
+		mails:    mails,
+	}
+
+	run.checkRoutes()
+	run.checkErrorCodes()
+	run.walk(s)
+	t.Logf("%d vérifications", run.checks)
+}
+
+// checkRoutes vérifie que routes et opérations décrites se correspondent. Les alias non versionnés sont rapprochés
+// de la route versionnée qu'ils dupliquent
+func (r *contractRun) checkRoutes() {
+	registered := map[string]bool{}
+	for _, route := range r.router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	documented := map[string]bool{}
+	for _, route := range r.router.Routes() {
+		r.checks++
+		if operation, found := r.contract.Find(route.Method, route.Path); found {
+			documented[operation.Method+" "+operation.Path] = true
+			continue
+		}
+		prefix := "/v1"
+		if !strings.HasPrefix(route.Path, prefix+"/") && registered[route.Method+" "+prefix+route.Path] {
+			continue // alias déprécié d'une route versionnée
+		}
+		r.fail("route %s %s non décrite", route.Method, route.Path)
+	}
+
+	for _, operation := range r.contract.Operations() {
+		r.checks++
+		if !documented[operation.Method+" "+operation.Path] {
+			r.fail("opération %s %s décrite mais non routée", operation.Method, operation.Path)
+		}
+	}
+}
+
+// checkErrorCodes vérifie que l'énumération ErrorCode du document est le catalogue des erreurs
+func (r *contractRun) checkErrorCodes() {
+	documented := r.contract.Enum("ErrorCode")
+	var catalog []string
+	for code := range apierror.Catalog {
+		catalog = append(catalog, string(code))
+	}
+	sort.Strings(documented)
+	sort.Strings(catalog)
+	r.checks++
+	if strings.Join(documented, ",") != strings.Join(catalog, ",") {
+		r.fail("ErrorCode %v ne correspond pas au catalogue %v", documented, catalog)
+	}
+}
+
+// walk parcourt l'API : sondes et documentation, inscription et sessions, tâches, utilisateurs et administration
+func (r *contractRun) walk(s *store.Store) {
+	const password = "Tr0ub4dor&3xyz!Q"
+	register := func(username string) string {
+		return fmt.Sprintf(`{"username":%q,"email":"%s@example.com","password":%q}`, username, username, password)
+	}
+	login := func(username string) string {
+		return fmt.Sprintf(`{"email":"%s@example.com","password":%q}`, username, password)
+	}
+
+	r.steps(
+		contractStep{method: "GET", path: "/healthz", status: 200},
+		contractStep{method: "GET", path: "/readyz", status: 200},
+		contractStep{method: "GET", path: "/version", status: 200},
+		contractStep{method: "GET", path: "/metrics", status: 200},
+		contractStep{method: "GET", path: "/problems", status: 200},
+		contractStep{method: "GET", path: "/problems/task_not_found", status: 200},
+		contractStep{method: "GET", path: "/problems/nope", status: 404},
+		contractStep{method: "GET", path: "/openapi.json", status: 200},
+		contractStep{method: "GET", path: "/openapi.yaml", status: 200},
+		contractStep{method: "GET", path: "/swagger/", status: 200},
+		contractStep{method: "GET", path: "/swagger/swagger-ui.css", status: 200},
+		contractStep{method: "GET", path: "/swagger/nope.js", status: 404},
+
+		contractStep{method: "POST", path: "/v1/auth/register", body: register("alice"), status: 201},
+		contractStep{method: "POST", path: "/v1/auth/register", body: register("bob"), status: 201},
+		contractStep{method: "POST", path: "/v1/auth/register", body: register("root"), status: 201},
+		contractStep{method: "POST", path: "/v1/auth/register", body: `{"username":"a!","email":"bad","password":"abc"}`, status: 400},
+		contractStep{method: "POST", path: "/v1/auth/register", body: register("alice"), status: 400},
+		contractStep{method: "POST", path: "/v1/auth/login", body: `{"email":"alice@example.com","password":"wrong"}`, status: 401},
+		contractStep{method: "POST", path: "/v1/auth/login", body: `{"email":"alice"}`, status: 400},
+	)
+	for _, username := range []string{"alice", "bob", "root"} {
+		r.login(username, login(username))
+	}
+	if root, err := s.Users.GetUserByUsername(context.Background(), "root"); err == nil {
+		root.IsAdmin = true
+		if err := s.Users.UpdateUser(context.Background(), root); err != nil {
+			r.fail("promotion du compte administrateur : %v", err)
+		}
+	}
+
+	r.steps(
+		contractStep{method: "PUT", path: "/v1/auth/language", body: `{"language":"en"}`, as: "bob", status: 200},
+		contractStep{method: "PUT", path: "/v1/auth/language", body: `{"language":"xx"}`, as: "bob", status: 400},
+		contractStep{method: "PUT", path: "/v1/auth/language", body: `{"language":"en"}`, status: 401},
+		contractStep{method: "PUT", path: "/v1/auth/password", body: `{"current_password":"wrong","new_password":"An0ther&Secret!"}`, as: "bob", status: 401},
+		contractStep{method: "PUT", path: "/v1/auth/password", body: fmt.Sprintf(`{"current_password":%q,"new_password":%q}`, password, password), as: "bob", status: 400},
+		contractStep{method: "GET", path: "/v1/auth/", status: 404},
+		contractStep{method: "GET", path: "/v1/auth/oidc/nope/login", status: 404},
+		contractStep{method: "GET", path: "/v1/auth/oidc/nope/callback?code=x&state=y", status: 404},
+		contractStep{method: "POST", path: "/v1/auth/magic-link", body: `{"email":"alice@example.com"}`, status: 202},
+		contractStep{method: "POST", path: "/v1/auth/magic-link", body: `{"email":"nobody@example.com"}`, status: 202},
+		contractStep{method: "POST", path: "/v1/auth/magic-link", body: `{}`, status: 400},
+		contractStep{method: "GET", path: "/v1/auth/magic-link/consume?token=abc", status: 200},
+	)
+	token := r.mails.lastToken()
+	r.steps(
+		contractStep{method: "POST", path: "/v1/auth/magic-link/consume", body: "token=" + url.QueryEscape(token), form: true, status: 200},
+		contractStep{method: "POST", path: "/v1/auth/magic-link/consume", body: "token=" + url.QueryEscape(token), form: true, status: 401},
+		contractStep{method: "POST", path: "/v1/auth/magic-link/consume", body: `{}`, status: 400},
+	)
+	//Une seule session par utilisateur : celle ouverte par le lien remplace la précédente
+	r.login("alice", login("alice"))
+	r.steps(
+
+		contractStep{method: "POST", path: "/v1/tasks/", body: `{"title":"Acheter du pain","status":"to-do"}`, as: "alice", status: 201},
+		contractStep{method: "POST", path: "/v1/tasks/", body: `{"title":"Arroser les plantes","status":"to-do","tags":["Maison","jardin"]}`, as: "alice", status: 201},
+		contractStep{method: "POST", path: "/v1/tasks/", body: `{"title":"Payer le loyer","status":"to-do","remind_at":"2030-01-05T08:00:00+01:00"}`, as: "alice", status: 201},
+		contractStep{method: "POST", path: "/v1/tasks/", body: `{"title":"Payer le loyer","status":"to-do","remind_at":"demain"}`, as: "alice", status: 400},
+		contractStep{method: "POST", path: "/v1/tasks/", body: `{"title":"  ","status":"nope","tags":["a b"]}`, as: "alice", status: 400},
+		contractStep{method: "POST", path: "/v1/tasks/", body: `{"title":"Sans session","status":"to-do"}`, status: 401},
+		contractStep{method: "GET", path: "/v1/tasks/", as: "alice", status: 200},
+		contractStep{method: "GET", path: "/v1/tasks/?status=done", as: "alice", status: 200},
+		contractStep{method: "GET", path: "/v1/tasks/?status=x", as: "alice", status: 400},
+		contractStep{method: "GET", path: "/v1/tasks/?tag=maison", as: "alice", status: 200},
+		contractStep{method: "GET", path: "/v1/tasks/?tag=a_b", as: "alice", status: 400},
+		contractStep{method: "GET", path: "/v1/tasks/?fields=id,title", as: "alice", status: 200},
+		contractStep{method: "GET", path: "/v1/tasks/?expand=user", as: "alice", status: 200},
+		contractStep{method: "GET", path: "/v1/tasks/?fields=password", as: "alice", status: 400},
+		contractStep{method: "PUT", path: "/v1/tasks/1", body: `{"status":"done"}`, as: "alice", status: 200},
+		contractStep{method: "PUT", path: "/v1/tasks/1?fields=status&expand=user", body: `{"title":"Acheter du pain complet"}`, as: "alice", status: 200},
+		contractStep{method: "PUT", path: "/v1/tasks/1", body: `{"tags":["courses"]}`, as: "alice", status: 200},
+		contractStep{method: "PUT", path: "/v1/tasks/1", body: `{"tags":["-"]}`, as: "alice", status: 400},
+		contractStep{method: "PUT", path: "/v1/tasks/3", body: `{"remind_at":null}`, as: "alice", status: 200},
+		contractStep{method: "PUT", path: "/v1/tasks/1", body: `{"user_id":2}`, as: "alice", status: 400},
+		contractStep{method: "PUT", path: "/v1/tasks/1", body: `{"status":"done"}`, as: "bob", status: 401},
+		contractStep{method: "PUT", path: "/v1/tasks/99", body: `{"status":"done"}`, as: "alice", status: 404},
+		contractStep{method: "PUT", path: "/v1/tasks/abc", body: `{"status":"done"}`, as: "alice", status: 400},
+		contractStep{method: "DELETE", path: "/v1/tasks/1", as: "bob", status: 401},
+		contractStep{method: "DELETE", path: "/v1/tasks/1", as: "alice", status: 200},
+		contractStep{method: "GET", path: "/tasks/", as: "alice", status: 200},
+
+		contractStep{method: "GET", path: "/v1/users/", status: 401},
+		contractStep{method: "GET", path: "/v1/users/", as: "alice", status: 403},
+		contractStep{method: "GET", path: "/v1/users/", as: "root", status: 200},
+		contractStep{method: "GET", path: "/v1/users/?fields=id,username", as: "root", status: 200},
+		contractStep{method: "GET", path: "/v1/users/?expand=user", as: "root", status: 400},
+		contractStep{method: "GET", path: "/v1/users/1", status: 401},
+		contractStep{method: "GET", path: "/v1/users/1", as: "bob", status: 403},
+		contractStep{method: "GET", path: "/v1/users/1", as: "alice", status: 200},
+		contractStep{method: "GET", path: "/v1/users/1", as: "root", status: 200},
+		contractStep{method: "GET", path: "/v1/users/99", as: "root", status: 404},
+		contractStep{method: "GET", path: "/v1/users/x", as: "root", status: 400},
+		contractStep{method: "POST", path: "/v1/users/", body: register("carol"), status: 401},
+		contractStep{method: "POST", path: "/v1/users/", body: register("carol"), as: "alice", status: 403},
+		contractStep{method: "POST", path: "/v1/users/", body: register("carol"), as: "root", status: 201},
+		contractStep{method: "POST", path: "/v1/users/", body: register("carol"), as: "root", status: 400},
+		contractStep{method: "POST", path: "/v1/users/", body: `{"username":"dave"}`, as: "root", status: 400},
+		contractStep{method: "PUT", path: "/v1/users/4", body: `{"username":"caroline","email":"carol@example.com"}`, status: 401},
+		contractStep{method: "PUT", path: "/v1/users/4", body: `{"username":"caroline","email":"carol@example.com"}`, as: "alice", status: 403},
+		contractStep{method: "PUT", path: "/v1/users/4", body: `{"username":"caroline","email":"carol@example.com"}`, as: "root", status: 200},
+		contractStep{method: "PUT", path: "/v1/users/4", body: `{"username":"alice","email":"carol@example.com"}`, as: "root", status: 400},
+		contractStep{method: "PUT", path: "/v1/users/99", body: `{"username":"nobody","email":"nobody@example.com"}`, as: "root", status: 404},
+		contractStep{method: "PUT", path: "/v1/users/1", body: `{"username":"alice","email":"alice.new@example.com"}`, as: "alice", status: 200},
+	)
+	//La nouvelle adresse n'est enregistrée qu'à la consommation du lien qui lui a été envoyé : elle ne permet pas
+	//d'obtenir un lien de connexion avant
+	confirmation := r.mails.last
+	if confirmation.To != "alice.new@example.com" {
+		r.fail("confirmation du changement d'adresse envoyée à %q", confirmation.To)
+	}
+	r.steps(contractStep{method: "POST", path: "/v1/auth/magic-link", body: `{"email":"alice.new@example.com"}`, status: 202})
+	if r.mails.last != confirmation {
+		r.fail("lien de connexion envoyé à une adresse non confirmée")
+	}
+	r.steps(
+		contractStep{method: "POST", path: "/v1/auth/magic-link/consume", body: `{"token":` + strconv.Quote(r.mails.lastToken()) + `}`, status: 200},
+		contractStep{method: "PUT", path: "/v1/users/1", body: `{"username":"alice","email":"alice@example.com"}`, as: "root", status: 200},
+	)
+	r.steps(contractStep{method: "POST", path: "/v1/auth/magic-link/consume", body: `{"token":` + strconv.Quote(r.mails.lastToken()) + `}`, status: 200})
+	r.login("alice", login("alice"))
+	r.steps(
+		contractStep{method: "DELETE", path: "/v1/users/", status: 400},
+		contractStep{method: "DELETE", path: "/v1/users/4", status: 401},
+		contractStep{method: "DELETE", path: "/v1/users/4", as: "alice", status: 403},
+		contractStep{method: "DELETE", path: "/v1/users/4", as: "root", status: 200},
+		contractStep{method: "DELETE", path: "/v1/users/4", as: "root", status: 404},
+
+		contractStep{method: "POST", path: "/v1/admin/users/2/unlock", as: "alice", status: 403},
+		contractStep{method: "POST", path: "/v1/admin/users/2/unlock", as: "root", status: 200},
+		contractStep{method: "POST", path: "/v1/admin/users/99/unlock", as: "root", status: 404},
+
+		contractStep{method: "POST", path: "/v1/auth/logout", as: "bob", status: 200},
+		contractStep{method: "POST", path: "/v1/auth/logout", as: "bob", status: 401},
+	)
+
+	//Compte désactivé par un administrateur : sa session est fermée et la connexion refusée
+	if alice, err := s.Users.GetUserByUsername(context.Background(), "alice"); err == nil {
+		now := time.Now()
+		if err := s.Users.SetDisabled(context.Background(), alice.ID, &now); err != nil {
+			r.fail("désactivation d'un compte : %v", err)
+		}
+	}
+	r.steps(
+		contractStep{method: "GET", path: "/v1/tasks/", as: "alice", status: 401},
+		contractStep{method: "POST", path: "/v1/auth/login", body: login("alice"), status: 403},
+	)
+}
+
+// login ouvre une session et conserve son jeton pour les étapes suivantes
+func (r *contractRun) login(username, body string) {
+	recorder := r.step(contractStep{method: "POST", path: "/v1/auth/login", body: body, status: 200})
+	if match := regexp.MustCompile(`"token":"([^"]+)"`).FindStringSubmatch(recorder.Body.String()); match != nil {
+		r.tokens[username] = match[1]
+	}
+}
+
+func (r *contractRun) steps(steps ...contractStep) {
+	for _, step := range steps {
+		r.step(step)
+	}
+}
+
+// step envoie une requête au routeur, vérifie son statut puis confronte la réponse au document
+func (r *contractRun) step(step contractStep) *httptest.ResponseRecorder {
+	r.checks++
+	request := httptest.NewRequest(step.method, step.path, strings.NewReader(step.body))
+	//Une adresse par requête : les limites de débit par IP ne doivent pas interrompre le parcours
+	request.RemoteAddr = fmt.Sprintf("198.51.100.%d:4000", r.checks%250+1)
+	if step.body != "" {
+		request.Header.Set("Content-Type", "application/json")
+		if step.form {
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if step.as != "" {
+		request.Header.Set("Authorization", "Bearer "+r.tokens[step.as])
+	}
+
+	recorder := httptest.NewRecorder()
+	r.router.ServeHTTP(recorder, request)
+	r.t.Logf("%d %s %s", recorder.Code, step.method, step.path)
+
+	if recorder.Code != step.status {
+		r.fail("%s %s : statut %d, %d attendu (%s)", step.method, step.path, recorder.Code, step.status, strings.TrimSpace(recorder.Body.String()))
+		return recorder
+	}
+
+	//Les alias non versionnés répondent comme la route versionnée, avec les en-têtes de dépréciation
+	path, _, _ := strings.Cut(step.path, "?")
+	if _, found := r.contract.Find(step.method, path); !found {
+		if recorder.Header().Get("Deprecation") == "" || recorder.Header().Get("Sunset") == "" {
+			r.fail("%s %s : alias sans en-têtes Deprecation et Sunset", step.method, path)
+		}
+		path = "/v1" + path
+	}
+	if err := r.contract.ValidateResponse(step.method, path, recorder.Code, recorder.Header(), recorder.Body.Bytes()); err != nil {
+		r.fail("%v", err)
+	}
+	return recorder
+}
+
+func (r *contractRun) fail(format string, args ...any) {
+	r.t.Helper()
+	r.t.Errorf(format, args...)
+}
+
+// capturedMails remplace l'envoi des emails : le jeton du dernier lien de connexion est relu par le parcours
+type capturedMails struct {
+	last pkg.Mail
+}
+
+func (m *capturedMails) Send(_ context.Context, mail pkg.Mail) error {
+	m.last = mail
+	return nil
+}
+
+func (m *capturedMails) lastToken() string {
+	match := regexp.MustCompile(`token=([^\s"&]+)`).FindStringSubmatch(m.last.Body)
+	if match == nil {
+		return ""
+	}
+	token, _ := url.QueryUnescape(match[1])
+	return token
+}
+
+var _ pkg.Mailer = (*capturedMails)(nil)
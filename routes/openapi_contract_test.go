@@ -0,0 +1,310 @@
+package routes_test
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"to-do-list-api/openapi"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// Emplacement sous lequel le document est chargé dans le compilateur de schémas
+const documentURL = "urn:to-do-list-api:openapi.json"
+
+// Méthodes HTTP pouvant porter une opération dans un objet Path Item
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// documentedOperation est une opération décrite par le document
+type documentedOperation struct {
+	Method string // en majuscules
+	Path   string // chemin complet, préfixe du serveur compris (ex. /v1/tasks/{id})
+
+	pointer string         // emplacement JSON de l'opération dans le document
+	item    map[string]any // objet Operation
+}
+
+// apiContract confronte des réponses réelles au document openapi/openapi.yaml. Il ne sert qu'aux tests : la
+// bibliothèque de validation des schémas n'est pas compilée dans le serveur
+type apiContract struct {
+	document   map[string]any
+	operations []documentedOperation
+	compiler   *jsonschema.Compiler
+	schemas    map[string]*jsonschema.Schema // schémas compilés, par emplacement
+}
+
+// loadContract charge le document et compile les schémas de toutes ses réponses, ce qui vérifie au passage
+// que chaque référence est résolue
+func loadContract() (*apiContract, error) {
+	raw, err := openapi.JSON()
+	if err != nil {
+		return nil, err
+	}
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	root, ok := document.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("openapi.yaml : le document n'est pas un objet")
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	if err := compiler.AddResource(documentURL, document); err != nil {
+		return nil, err
+	}
+
+	contract := &apiContract{document: root, compiler: compiler, schemas: map[string]*jsonschema.Schema{}}
+	if err := contract.collectOperations(); err != nil {
+		return nil, err
+	}
+	for _, operation := range contract.operations {
+		responses, _ := operation.item["responses"].(map[string]any)
+		if len(responses) == 0 {
+			return nil, fmt.Errorf("%s %s : aucune réponse décrite", operation.Method, operation.Path)
+		}
+		for status := range responses {
+			response, pointer, err := contract.resolve(responses[status], operation.pointer+"/responses/"+escape(status))
+			if err != nil {
+				return nil, fmt.Errorf("%s %s, réponse %s : %w", operation.Method, operation.Path, status, err)
+			}
+			content, _ := response["content"].(map[string]any)
+			for mediaType := range content {
+				if _, err := contract.schema(pointer + "/content/" + escape(mediaType) + "/schema"); err != nil {
+					return nil, fmt.Errorf("%s %s, réponse %s (%s) : %w", operation.Method, operation.Path, status, mediaType, err)
+				}
+			}
+		}
+	}
+	return contract, nil
+}
+
+// collectOperations liste les opérations, avec le préfixe du serveur qui s'applique à chaque chemin
+func (ct *apiContract) collectOperations() error {
+	prefix := serverPrefix(ct.document["servers"])
+	paths, _ := ct.document["paths"].(map[string]any)
+	for path, value := range paths {
+		item, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("openapi.yaml : chemin %s invalide", path)
+		}
+		itemPrefix := prefix
+		if servers, exists := item["servers"]; exists {
+			itemPrefix = serverPrefix(servers)
+		}
+		for _, method := range methods {
+			operation, exists := item[method].(map[string]any)
+			if !exists {
+				continue
+			}
+			ct.operations = append(ct.operations, documentedOperation{
+				Method:  strings.ToUpper(method),
+				Path:    itemPrefix + path,
+				pointer: "/paths/" + escape(path) + "/" + method,
+				item:    operation,
+			})
+		}
+	}
+	sort.Slice(ct.operations, func(i, j int) bool {
+		if ct.operations[i].Path != ct.operations[j].Path {
+			return ct.operations[i].Path < ct.operations[j].Path
+		}
+		return ct.operations[i].Method < ct.operations[j].Method
+	})
+	return nil
+}
+
+// Operations renvoie les opérations décrites, triées par chemin puis par méthode
+func (ct *apiContract) Operations() []documentedOperation {
+	return ct.operations
+}
+
+// Enum renvoie les valeurs de l'énumération du schéma name (components/schemas)
+func (ct *apiContract) Enum(name string) []string {
+	schema, _ := ct.lookup("/components/schemas/" + escape(name)).(map[string]any)
+	values, _ := schema["enum"].([]any)
+	var enum []string
+	for _, value := range values {
+		if text, ok := value.(string); ok {
+			enum = append(enum, text)
+		}
+	}
+	return enum
+}
+
+// Find renvoie l'opération décrivant method sur path, chemin concret (/v1/tasks/3) ou modèle ({id} ou :id).
+// Lorsque plusieurs modèles conviennent, celui dont le plus de segments sont littéraux l'emporte
+func (ct *apiContract) Find(method, path string) (documentedOperation, bool) {
+	segments := strings.Split(path, "/")
+	best, bestScore := documentedOperation{}, -1
+	for _, operation := range ct.operations {
+		if operation.Method != method {
+			continue
+		}
+		if score := matchTemplate(strings.Split(operation.Path, "/"), segments); score > bestScore {
+			best, bestScore = operation, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// ValidateResponse vérifie qu'une réponse reçue pour method et path est décrite par le document : statut
+// (ou réponse default), type de contenu et, pour le JSON, conformité du corps au schéma
+func (ct *apiContract) ValidateResponse(method, path string, status int, header http.Header, body []byte) error {
+	operation, found := ct.Find(method, path)
+	if !found {
+		return fmt.Errorf("%s %s : opération non décrite", method, path)
+	}
+	responses, _ := operation.item["responses"].(map[string]any)
+	key := strconv.Itoa(status)
+	value, exists := responses[key]
+	if !exists {
+		if value, exists = responses["default"]; !exists {
+			return fmt.Errorf("%s %s : statut %d non décrit", method, operation.Path, status)
+		}
+		key = "default"
+	}
+	response, pointer, err := ct.resolve(value, operation.pointer+"/responses/"+key)
+	if err != nil {
+		return err
+	}
+
+	content, _ := response["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil // réponse sans corps décrit (redirection)
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("%s %s (%d) : Content-Type %q illisible", method, operation.Path, status, header.Get("Content-Type"))
+	}
+	documented := matchMediaType(content, mediaType)
+	if documented == "" {
+		return fmt.Errorf("%s %s (%d) : Content-Type %s non décrit", method, operation.Path, status, mediaType)
+	}
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+
+	schema, err := ct.schema(pointer + "/content/" + escape(documented) + "/schema")
+	if err != nil {
+		return err
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s %s (%d) : corps JSON illisible : %w", method, operation.Path, status, err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		return fmt.Errorf("%s %s (%d) : %w", method, operation.Path, status, err)
+	}
+	return nil
+}
+
+// resolve suit la référence locale ($ref) d'un objet du document et renvoie l'objet et son emplacement
+func (ct *apiContract) resolve(value any, pointer string) (map[string]any, string, error) {
+	for range 8 {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, "", fmt.Errorf("%s : objet attendu", pointer)
+		}
+		ref, isRef := object["$ref"].(string)
+		if !isRef {
+			return object, pointer, nil
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, "", fmt.Errorf("%s : seules les références locales sont prises en charge (%s)", pointer, ref)
+		}
+		pointer = ref[1:]
+		if value = ct.lookup(pointer); value == nil {
+			return nil, "", fmt.Errorf("référence %s introuvable", ref)
+		}
+	}
+	return nil, "", fmt.Errorf("%s : références en boucle", pointer)
+}
+
+// lookup renvoie la valeur du document à l'emplacement JSON pointer, ou nil
+func (ct *apiContract) lookup(pointer string) any {
+	var value any = ct.document
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[unescape(token)]
+	}
+	return value
+}
+
+// schema compile (une seule fois) le schéma situé à l'emplacement pointer
+func (ct *apiContract) schema(pointer string) (*jsonschema.Schema, error) {
+	if schema, exists := ct.schemas[pointer]; exists {
+		return schema, nil
+	}
+	schema, err := ct.compiler.Compile(documentURL + "#" + pointer)
+	if err != nil {
+		return nil, err
+	}
+	ct.schemas[pointer] = schema
+	return schema, nil
+}
+
+// serverPrefix renvoie le chemin du premier serveur d'une liste servers, sans barre oblique finale
+func serverPrefix(servers any) string {
+	list, _ := servers.([]any)
+	if len(list) == 0 {
+		return ""
+	}
+	server, _ := list[0].(map[string]any)
+	url, _ := server["url"].(string)
+	return strings.TrimSuffix(url, "/")
+}
+
+// matchTemplate compare les segments d'un modèle de chemin à ceux d'un chemin. Elle renvoie le nombre de segments
+// littéraux communs, ou -1 si le chemin ne correspond pas
+func matchTemplate(template, segments []string) int {
+	if len(template) != len(segments) {
+		return -1
+	}
+	score := 0
+	for i, segment := range template {
+		switch {
+		case isParameter(segment):
+		case segment == segments[i]:
+			score++
+		default:
+			return -1
+		}
+	}
+	return score
+}
+
+// isParameter indique si un segment est un paramètre : {id} dans le document, :id ou *file dans gin
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") ||
+		strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
+
+// matchMediaType renvoie l'entrée de content qui décrit mediaType (exacte, type/* ou */*), ou ""
+func matchMediaType(content map[string]any, mediaType string) string {
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	for _, candidate := range []string{mediaType, mainType + "/*", "*/*"} {
+		if _, exists := content[candidate]; exists {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// escape et unescape encodent un segment de JSON pointer (RFC 6901)
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func unescape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
@@ -1,22 +1,33 @@
 package routes
 
 import (
-	"net/http"
+	"time"
+	"to-do-list-api/apierror"
 	"to-do-list-api/controllers"
+	"to-do-list-api/metrics"
 	"to-do-list-api/middlewares"
+	"to-do-list-api/openapi"
+	"to-do-list-api/pkg"
+	"to-do-list-api/store"
 
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-// @title To-Do List API
-// @version 1.0
-// @description This is a simple To-Do List API.
-// @host localhost:8080
-// @BasePath /
-func SetupRouter() *gin.Engine {
-	router := gin.Default()
+// Politiques de limitation de débit par groupe de routes
+var (
+	authRateLimit     = middlewares.RateLimitPolicy{Name: "auth", Limit: pkg.RateLimit{Requests: 20, Period: time.Minute}}
+	registerRateLimit = middlewares.RateLimitPolicy{Name: "register", Limit: pkg.RateLimit{Requests: 5, Period: time.Hour}}
+	userRateLimit     = middlewares.RateLimitPolicy{Name: "users", Limit: pkg.RateLimit{Requests: 60, Period: time.Minute}}
+	taskRateLimit     = middlewares.RateLimitPolicy{Name: "tasks", Limit: pkg.RateLimit{Requests: 120, Period: time.Minute}}
+	taskCreationLimit = middlewares.RateLimitPolicy{Name: "task-creation", Limit: pkg.RateLimit{Requests: 30, Period: time.Minute, Burst: 10}}
+)
+
+// SetupRouter construit le routeur de l'API. Les routes sont décrites dans openapi/openapi.yaml
+func SetupRouter(s *store.Store) *gin.Engine {
+	router := gin.New()
+	h := controllers.NewHandlers(s)
+	//Chaque middleware et contrôleur de l'API a son propre span dans la trace de la requête
+	traced := middlewares.Traced
 
 	// Aucune confiance envers les proxies
 	err := router.SetTrustedProxies(nil)
@@ -24,42 +35,99 @@ func SetupRouter() *gin.Engine {
 		panic(err)
 	}
 
-	// Swagger route
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	// Identifiant de requête, span de la requête, journal des accès et métriques, puis récupération des paniques
+	// (placée après pour que les requêtes ayant paniqué soient journalisées et comptées avec leur code 500)
+	// et langue des messages
+	router.Use(middlewares.RequestID(), middlewares.Tracing(), middlewares.AccessLog(), middlewares.Metrics(), middlewares.Recovery(), middlewares.Language())
+
+	// Sondes de l'orchestrateur, informations de build et métriques Prometheus, enregistrées avant les
+	// middlewares suivants pour ne pas être soumises à la protection CSRF ni à la limitation de débit
+	router.GET("/healthz", h.Healthz)
+	router.GET("/readyz", h.Readyz)
+	router.GET("/version", h.Version)
+	router.GET("/metrics", gin.WrapH(metrics.Handler(s)))
+
+	// Catalogue des erreurs : le membre "type" d'une réponse d'erreur renvoie vers /problems/{code}
+	router.GET("/problems", apierror.ListProblems)
+	router.GET("/problems/:code", apierror.GetProblem)
+	router.NoRoute(apierror.NotFound)
+
+	// Description OpenAPI 3.1 de l'API et interface Swagger UI
+	router.GET("/openapi.json", openapi.ServeJSON)
+	router.GET("/openapi.yaml", openapi.ServeYAML)
+	router.GET("/swagger/*file", openapi.SwaggerUI)
+
+	// Protection CSRF des requêtes authentifiées par cookie
+	router.Use(traced(middlewares.CSRFProtection()))
+
+	//Versions de l'API, chacune sous son préfixe
+	for _, version := range versions {
+		version.register(router.Group(version.prefix), h, s)
+	}
+
+	//Alias non versionnés, conservés pour les clients existants et signalés comme dépréciés
+	if LegacyRoutes {
+		legacy := router.Group("/", middlewares.Deprecated(LegacyDeprecatedSince, LegacySunset, legacyVersion.prefix))
+		legacyVersion.register(legacy, h, s)
+	}
+
+	return router
+}
+
+// registerV1 enregistre les routes de la version 1 de l'API dans le groupe api
+func registerV1(api *gin.RouterGroup, h *controllers.Handlers, s *store.Store) {
+	traced := middlewares.Traced
+	authRequired := traced(middlewares.AuthRequired(s.Sessions, s.Users))
 
 	//Routes pour l'authentification
-	authRoutes := router.Group("/auth")
+	authRoutes := api.Group("/auth")
+	authRoutes.Use(traced(middlewares.RateLimit(pkg.DefaultRateLimitStore, authRateLimit)))
 	{
-		authRoutes.POST("/register", controllers.Register)
-		authRoutes.POST("/login", controllers.Login)
-		authRoutes.POST("/logout", middlewares.AuthRequired(), controllers.Logout)
+		authRoutes.POST("/register", traced(middlewares.RateLimit(pkg.DefaultRateLimitStore, registerRateLimit)), traced(h.Register))
+		authRoutes.POST("/login", traced(h.Login))
+		authRoutes.POST("/logout", authRequired, traced(h.Logout))
+		authRoutes.PUT("/password", authRequired, traced(h.ChangePassword))
+		authRoutes.PUT("/language", authRequired, traced(h.ChangeLanguage))
+		authRoutes.GET("/oidc/:provider/login", traced(h.OIDCLogin))
+		authRoutes.GET("/oidc/:provider/callback", traced(h.OIDCCallback))
+		authRoutes.POST("/magic-link", traced(h.RequestMagicLink))
+		authRoutes.GET("/magic-link/consume", traced(h.ConfirmMagicLink))
+		authRoutes.POST("/magic-link/consume", traced(h.ConsumeMagicLink))
 		authRoutes.GET("/", func(c *gin.Context) {
-			c.JSON(http.StatusNotFound, gin.H{"message": "Inscription ? Connexion ? Ou déconnexion ?"})
+			apierror.Respond(c, apierror.CodeNotFound, "request.auth_index")
 		})
 	}
 
 	//Routes pour les utilisateurs
-	userRoutes := router.Group("/users")
+	userRoutes := api.Group("/users")
+	userRoutes.Use(traced(middlewares.RateLimit(pkg.DefaultRateLimitStore, userRateLimit)))
 	{
-		userRoutes.GET("/", controllers.GetUsers)
-		userRoutes.GET("/:id", controllers.GetUser)
-		userRoutes.PUT("/:id", controllers.UpdateUser)
-		userRoutes.POST("/", controllers.CreateUser)
-		userRoutes.DELETE("/:id", controllers.DeleteUser)
+		//Les emails et les rôles ne sont visibles que des administrateurs, ou du titulaire pour son propre compte
+		userRoutes.GET("/", authRequired, traced(middlewares.AdminRequired()), traced(h.GetUsers))
+		userRoutes.GET("/:id", authRequired, traced(middlewares.SelfOrAdminRequired()), traced(h.GetUser))
+		//Les inscriptions passent par /auth/register ; un compte n'est modifié que par son titulaire ou un administrateur
+		userRoutes.PUT("/:id", authRequired, traced(middlewares.SelfOrAdminRequired()), traced(h.UpdateUser))
+		userRoutes.POST("/", authRequired, traced(middlewares.AdminRequired()), traced(h.CreateUser))
+		userRoutes.DELETE("/:id", authRequired, traced(middlewares.SelfOrAdminRequired()), traced(h.DeleteUser))
 		userRoutes.DELETE("/", func(c *gin.Context) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "L'ID est requis pour cette opération"})
+			apierror.Respond(c, apierror.CodeInvalidRequest, "request.id_required")
 		})
 	}
 
 	//Routes pour les tâches
-	taskRoutes := router.Group("/tasks")
-	taskRoutes.Use(middlewares.AuthRequired())
+	taskRoutes := api.Group("/tasks")
+	taskRoutes.Use(authRequired, traced(middlewares.RateLimit(pkg.DefaultRateLimitStore, taskRateLimit)))
 	{
-		taskRoutes.GET("/", middlewares.AuthorizeTaskOwnerShip(), controllers.GetTasks)
-		taskRoutes.POST("/", middlewares.AuthorizeTaskOwnerShip(), controllers.CreateTask)
-		taskRoutes.PUT("/:id", middlewares.AuthorizeTaskOwnerShip(), controllers.UpdateTask)
-		taskRoutes.DELETE("/:id", middlewares.AuthorizeTaskOwnerShip(), controllers.DeleteTask)
+		taskRoutes.GET("/", traced(h.GetTasks))
+		taskRoutes.POST("/", traced(middlewares.RateLimit(pkg.DefaultRateLimitStore, taskCreationLimit)), traced(h.CreateTask))
+		taskRoutes.PUT("/:id", traced(middlewares.AuthorizeTaskOwnerShip(s.Tasks)), traced(h.UpdateTask))
+		taskRoutes.DELETE("/:id", traced(middlewares.AuthorizeTaskOwnerShip(s.Tasks)), traced(h.DeleteTask))
 	}
 
-	return router
+	//Routes d'administration
+	adminRoutes := api.Group("/admin")
+	adminRoutes.Use(authRequired, traced(middlewares.AdminRequired()))
+	{
+		adminRoutes.POST("/users/:id/unlock", traced(h.UnlockUser))
+	}
 }
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"time"
+	"to-do-list-api/controllers"
+	"to-do-list-api/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiVersion décrit une version de l'API : son préfixe et l'enregistrement de ses routes. Chaque version a sa propre
+// fonction d'enregistrement, libre de monter d'autres contrôleurs que les versions précédentes
+type apiVersion struct {
+	prefix   string
+	register func(api *gin.RouterGroup, h *controllers.Handlers, s *store.Store)
+}
+
+var v1 = apiVersion{prefix: "/v1", register: registerV1}
+
+// versions liste les versions servies simultanément ; une v2 s'ajoute ici sans modifier les routes de la v1
+var versions = []apiVersion{v1}
+
+// legacyVersion est la version servie sans préfixe, pour les clients antérieurs au versionnement
+var legacyVersion = v1
+
+// Alias non versionnés (/tasks, /auth/login...) : activés par défaut, dépréciés depuis l'introduction de /v1
+var (
+	LegacyRoutes          = true
+	LegacyDeprecatedSince = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	LegacySunset          = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC) // date de retrait annoncée (en-tête Sunset)
+)
@@ -0,0 +1,370 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"to-do-list-api/migrations"
+	"to-do-list-api/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NewGormStore renvoie les dépôts adossés à une base GORM
+func NewGormStore(db *gorm.DB) *Store {
+	return &Store{
+		Tasks:      &gormTaskStore{db},
+		Users:      &gormUserStore{db},
+		Sessions:   &gormSessionStore{db},
+		Identities: &gormIdentityStore{db},
+		MagicLinks: &gormMagicLinkStore{db},
+		Jobs:       &gormJobStore{db},
+		Health:     &gormHealthStore{db},
+	}
+}
+
+// translate convertit les erreurs GORM en erreurs du package
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	}
+	return err
+}
+
+type gormTaskStore struct{ db *gorm.DB }
+
+func (s *gormTaskStore) CountTasksByStatus(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Task{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (s *gormTaskStore) ListTasks(ctx context.Context, filter TaskFilter) ([]models.Task, error) {
+	query := s.db.WithContext(ctx)
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Tag != "" {
+		//Étiquettes stockées en tableau JSON ; leur format exclut les guillemets et les jokers de LIKE. La collation
+		//utf8mb4_unicode_ci de MySQL ignore les accents : la comparaison y est binaire, comme sur les autres moteurs
+		condition := "tags LIKE ?"
+		if s.db.Dialector.Name() == "mysql" {
+			condition = "tags LIKE ? COLLATE utf8mb4_bin"
+		}
+		query = query.Where(condition, `%"`+filter.Tag+`"%`)
+	}
+
+	var tasks []models.Task
+	if err := query.Order("id").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (s *gormTaskStore) GetTask(ctx context.Context, id uint) (*models.Task, error) {
+	var task models.Task
+	if err := s.db.WithContext(ctx).First(&task, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &task, nil
+}
+
+func (s *gormTaskStore) CreateTask(ctx context.Context, task *models.Task) error {
+	return translate(s.db.WithContext(ctx).Create(task).Error)
+}
+
+func (s *gormTaskStore) UpdateTask(ctx context.Context, task *models.Task) error {
+	return translate(s.db.WithContext(ctx).Save(task).Error)
+}
+
+func (s *gormTaskStore) DeleteTask(ctx context.Context, id uint) error {
+	return s.db.WithContext(ctx).Delete(&models.Task{}, id).Error
+}
+
+func (s *gormTaskStore) PurgeDeletedTasks(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&models.Task{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *gormTaskStore) ListDueReminders(ctx context.Context, now time.Time, maxAttempts, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+	err := s.db.WithContext(ctx).Preload("User").
+		Where("remind_at <= ? AND reminded_at IS NULL AND status <> ? AND reminder_attempts < ?", now, "done", maxAttempts).
+		Order("reminder_attempts, remind_at, id").Limit(limit).Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (s *gormTaskStore) MarkReminded(ctx context.Context, id uint, at time.Time) error {
+	//UpdateColumn laisse updated_at intact : l'envoi du rappel ne modifie pas la tâche
+	return s.db.WithContext(ctx).Model(&models.Task{}).Where("id = ?", id).UpdateColumn("reminded_at", at).Error
+}
+
+func (s *gormTaskStore) RecordReminderFailure(ctx context.Context, id uint) error {
+	return s.db.WithContext(ctx).Model(&models.Task{}).Where("id = ?", id).
+		UpdateColumn("reminder_attempts", gorm.Expr("reminder_attempts + 1")).Error
+}
+
+type gormUserStore struct{ db *gorm.DB }
+
+func (s *gormUserStore) ListUsers(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	if err := s.db.WithContext(ctx).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (s *gormUserStore) GetUser(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (s *gormUserStore) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (s *gormUserStore) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("LOWER(username) = ?", strings.ToLower(username)).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (s *gormUserStore) CreateUser(ctx context.Context, user *models.User) error {
+	return translate(s.db.WithContext(ctx).Create(user).Error)
+}
+
+func (s *gormUserStore) UpdateUser(ctx context.Context, user *models.User) error {
+	return translate(s.db.WithContext(ctx).Save(user).Error)
+}
+
+func (s *gormUserStore) SetPassword(ctx context.Context, id uint, hash string) error {
+	return s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Update("password", hash).Error
+}
+
+func (s *gormUserStore) SetLoginFailures(ctx context.Context, id uint, attempts int, lockedUntil *time.Time) error {
+	return s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"failed_login_attempts": attempts,
+		"locked_until":          lockedUntil,
+	}).Error
+}
+
+func (s *gormUserStore) SetDisabled(ctx context.Context, id uint, disabledAt *time.Time) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).Where("id = ?", id).Update("disabled_at", disabledAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		if disabledAt == nil {
+			return nil
+		}
+		return tx.Where("user_id = ?", id).Delete(&models.Session{}).Error
+	})
+}
+
+func (s *gormUserStore) DeleteUser(ctx context.Context, id uint) error {
+	//Les sessions ne sont pas liées aux utilisateurs par une clé étrangère : elles sont supprimées explicitement
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.User{}, id).Error
+	})
+}
+
+type gormSessionStore struct{ db *gorm.DB }
+
+func (s *gormSessionStore) CountActiveSessions(ctx context.Context, now time.Time) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.Session{}).Where("expires_at > ?", now).Count(&count).Error
+	return count, err
+}
+
+func (s *gormSessionStore) GetSession(ctx context.Context, token string) (*models.Session, error) {
+	var session models.Session
+	if err := s.db.WithContext(ctx).Where("token = ?", token).First(&session).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &session, nil
+}
+
+func (s *gormSessionStore) ReplaceSession(ctx context.Context, session *models.Session) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", session.UserID).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		return translate(tx.Create(session).Error)
+	})
+}
+
+func (s *gormSessionStore) DeleteSession(ctx context.Context, id uint) error {
+	return s.db.WithContext(ctx).Delete(&models.Session{}, id).Error
+}
+
+func (s *gormSessionStore) DeleteUserSessions(ctx context.Context, userID uint) (int64, error) {
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *gormSessionStore) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *gormSessionStore) DeleteAllSessions(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
+
+type gormIdentityStore struct{ db *gorm.DB }
+
+func (s *gormIdentityStore) GetIdentity(ctx context.Context, provider, subject string) (*models.ExternalIdentity, error) {
+	var identity models.ExternalIdentity
+	err := s.db.WithContext(ctx).Preload("User").Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &identity, nil
+}
+
+func (s *gormIdentityStore) CreateIdentity(ctx context.Context, identity *models.ExternalIdentity) error {
+	return translate(s.db.WithContext(ctx).Omit("User").Create(identity).Error)
+}
+
+type gormMagicLinkStore struct{ db *gorm.DB }
+
+func (s *gormMagicLinkStore) GetMagicLink(ctx context.Context, tokenHash string) (*models.MagicLink, error) {
+	var link models.MagicLink
+	if err := s.db.WithContext(ctx).Preload("User").Where("token_hash = ?", tokenHash).First(&link).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &link, nil
+}
+
+func (s *gormMagicLinkStore) ReplaceMagicLink(ctx context.Context, link *models.MagicLink) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", link.UserID).Delete(&models.MagicLink{}).Error; err != nil {
+			return err
+		}
+		return translate(tx.Omit("User").Create(link).Error)
+	})
+}
+
+func (s *gormMagicLinkStore) ConsumeMagicLink(ctx context.Context, id uint, now time.Time) (bool, error) {
+	//Mise à jour conditionnelle atomique : seule la première consommation modifie la ligne
+	result := s.db.WithContext(ctx).Model(&models.MagicLink{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+type gormJobStore struct{ db *gorm.DB }
+
+func (s *gormJobStore) AcquireJobLock(ctx context.Context, name, owner string, now, until time.Time) (bool, error) {
+	//Reprise d'un verrou expiré (ou déjà détenu) par une mise à jour conditionnelle, sinon création : dans les deux cas
+	//une seule instance l'emporte
+	result := s.db.WithContext(ctx).Model(&models.JobLock{}).
+		Where("name = ? AND (locked_until <= ? OR owner = ?)", name, now, owner).
+		Updates(map[string]any{"owner": owner, "locked_until": until})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	//Première exécution de la tâche : l'instance qui perd la course à l'insertion ne provoque pas d'erreur
+	result = s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.JobLock{Name: name, Owner: owner, LockedUntil: until})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *gormJobStore) ReleaseJobLock(ctx context.Context, name, owner string, until time.Time) error {
+	return s.db.WithContext(ctx).Model(&models.JobLock{}).
+		Where("name = ? AND owner = ?", name, owner).
+		Update("locked_until", until).Error
+}
+
+func (s *gormJobStore) CreateJobRun(ctx context.Context, run *models.JobRun) error {
+	return s.db.WithContext(ctx).Create(run).Error
+}
+
+func (s *gormJobStore) UpdateJobRun(ctx context.Context, run *models.JobRun) error {
+	return s.db.WithContext(ctx).Save(run).Error
+}
+
+func (s *gormJobStore) ListJobRuns(ctx context.Context, job string, limit int) ([]models.JobRun, error) {
+	query := s.db.WithContext(ctx)
+	if job != "" {
+		query = query.Where("job = ?", job)
+	}
+	var runs []models.JobRun
+	if err := query.Order("id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (s *gormJobStore) PruneJobRuns(ctx context.Context, job string, keep int) (int64, error) {
+	//Identifiant de la plus ancienne exécution conservée ; s'il n'y en a pas autant, rien n'est supprimé
+	var oldest []uint
+	err := s.db.WithContext(ctx).Model(&models.JobRun{}).Where("job = ?", job).
+		Order("id DESC").Offset(keep-1).Limit(1).Pluck("id", &oldest).Error
+	if err != nil || len(oldest) == 0 {
+		return 0, err
+	}
+	result := s.db.WithContext(ctx).Where("job = ? AND id < ?", job, oldest[0]).Delete(&models.JobRun{})
+	return result.RowsAffected, result.Error
+}
+
+type gormHealthStore struct{ db *gorm.DB }
+
+func (s *gormHealthStore) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (s *gormHealthStore) CheckSchema(ctx context.Context) error {
+	return migrations.CheckCurrent(s.db.WithContext(ctx))
+}
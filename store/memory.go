@@ -0,0 +1,604 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"to-do-list-api/models"
+
+	"gorm.io/gorm"
+)
+
+// memoryData contient l'ensemble des enregistrements, partagés par les dépôts en mémoire
+// afin de respecter les relations (suppression en cascade, chargement de l'utilisateur)
+type memoryData struct {
+	mu         sync.RWMutex
+	lastIDs    map[string]uint // dernier identifiant attribué, par table
+	tasks      map[uint]models.Task
+	users      map[uint]models.User
+	sessions   map[uint]models.Session
+	identities map[uint]models.ExternalIdentity
+	magicLinks map[uint]models.MagicLink
+	jobRuns    map[uint]models.JobRun
+	jobLocks   map[string]models.JobLock
+}
+
+// NewMemoryStore renvoie des dépôts en mémoire, sans persistance, pour les tests et les démonstrations
+func NewMemoryStore() *Store {
+	data := &memoryData{
+		lastIDs:    make(map[string]uint),
+		tasks:      make(map[uint]models.Task),
+		users:      make(map[uint]models.User),
+		sessions:   make(map[uint]models.Session),
+		identities: make(map[uint]models.ExternalIdentity),
+		magicLinks: make(map[uint]models.MagicLink),
+		jobRuns:    make(map[uint]models.JobRun),
+		jobLocks:   make(map[string]models.JobLock),
+	}
+	return &Store{
+		Tasks:      &memoryTaskStore{data},
+		Users:      &memoryUserStore{data},
+		Sessions:   &memorySessionStore{data},
+		Identities: &memoryIdentityStore{data},
+		MagicLinks: &memoryMagicLinkStore{data},
+		Jobs:       &memoryJobStore{data},
+		Health:     memoryHealthStore{},
+	}
+}
+
+// newID attribue le prochain identifiant de la table, comme une clé auto-incrémentée ;
+// l'appelant détient le verrou en écriture
+func (d *memoryData) newID(table string) uint {
+	d.lastIDs[table]++
+	return d.lastIDs[table]
+}
+
+// sortedIDs renvoie les clés d'une table par ordre croissant
+func sortedIDs[T any](table map[uint]T) []uint {
+	ids := make([]uint, 0, len(table))
+	for id := range table {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+type memoryTaskStore struct{ data *memoryData }
+
+func (s *memoryTaskStore) CountTasksByStatus(ctx context.Context) (map[string]int64, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	counts := make(map[string]int64)
+	for _, task := range s.data.tasks {
+		if !task.DeletedAt.Valid {
+			counts[task.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func (s *memoryTaskStore) ListTasks(ctx context.Context, filter TaskFilter) ([]models.Task, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, id := range sortedIDs(s.data.tasks) {
+		task := s.data.tasks[id]
+		if !task.DeletedAt.Valid && (filter.UserID == 0 || task.UserID == filter.UserID) && (filter.Status == "" || task.Status == filter.Status) &&
+			(filter.Tag == "" || slices.Contains(task.Tags, filter.Tag)) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (s *memoryTaskStore) GetTask(ctx context.Context, id uint) (*models.Task, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	task, exists := s.data.tasks[id]
+	if !exists || task.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	return &task, nil
+}
+
+func (s *memoryTaskStore) CreateTask(ctx context.Context, task *models.Task) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if _, exists := s.data.users[task.UserID]; !exists {
+		return ErrNotFound
+	}
+	now := time.Now()
+	task.ID = s.data.newID("tasks")
+	task.CreatedAt, task.UpdatedAt = now, now
+	task.User = models.User{}
+	stored := *task
+	stored.Tags = slices.Clone(task.Tags)
+	s.data.tasks[task.ID] = stored
+	return nil
+}
+
+func (s *memoryTaskStore) UpdateTask(ctx context.Context, task *models.Task) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if existing, exists := s.data.tasks[task.ID]; !exists || existing.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	task.UpdatedAt = time.Now()
+	stored := *task
+	stored.Tags = slices.Clone(task.Tags)
+	s.data.tasks[task.ID] = stored
+	return nil
+}
+
+func (s *memoryTaskStore) DeleteTask(ctx context.Context, id uint) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if task, exists := s.data.tasks[id]; exists && !task.DeletedAt.Valid {
+		task.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		s.data.tasks[id] = task
+	}
+	return nil
+}
+
+func (s *memoryTaskStore) PurgeDeletedTasks(ctx context.Context, before time.Time) (int64, error) {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	var purged int64
+	for id, task := range s.data.tasks {
+		if task.DeletedAt.Valid && task.DeletedAt.Time.Before(before) {
+			delete(s.data.tasks, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (s *memoryTaskStore) ListDueReminders(ctx context.Context, now time.Time, maxAttempts, limit int) ([]models.Task, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, id := range sortedIDs(s.data.tasks) {
+		task := s.data.tasks[id]
+		if !task.DeletedAt.Valid && task.RemindAt != nil && !task.RemindAt.After(now) && task.RemindedAt == nil && task.Status != "done" &&
+			task.ReminderAttempts < maxAttempts {
+			task.User = s.data.users[task.UserID]
+			tasks = append(tasks, task)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].ReminderAttempts != tasks[j].ReminderAttempts {
+			return tasks[i].ReminderAttempts < tasks[j].ReminderAttempts
+		}
+		return tasks[i].RemindAt.Before(*tasks[j].RemindAt)
+	})
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+func (s *memoryTaskStore) MarkReminded(ctx context.Context, id uint, at time.Time) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if task, exists := s.data.tasks[id]; exists {
+		task.RemindedAt = &at
+		s.data.tasks[id] = task
+	}
+	return nil
+}
+
+func (s *memoryTaskStore) RecordReminderFailure(ctx context.Context, id uint) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if task, exists := s.data.tasks[id]; exists {
+		task.ReminderAttempts++
+		s.data.tasks[id] = task
+	}
+	return nil
+}
+
+type memoryUserStore struct{ data *memoryData }
+
+func (s *memoryUserStore) ListUsers(ctx context.Context) ([]models.User, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	users := []models.User{}
+	for _, id := range sortedIDs(s.data.users) {
+		users = append(users, s.data.users[id])
+	}
+	return users, nil
+}
+
+func (s *memoryUserStore) GetUser(ctx context.Context, id uint) (*models.User, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	user, exists := s.data.users[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (s *memoryUserStore) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	for _, user := range s.data.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryUserStore) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	for _, user := range s.data.users {
+		if strings.EqualFold(user.Username, username) {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// conflicts indique si un autre utilisateur porte déjà cet email ou ce username ; le verrou est détenu
+func (s *memoryUserStore) conflicts(user *models.User) bool {
+	for id, existing := range s.data.users {
+		if id != user.ID && (existing.Email == user.Email || strings.EqualFold(existing.Username, user.Username)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memoryUserStore) CreateUser(ctx context.Context, user *models.User) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if s.conflicts(user) {
+		return ErrDuplicate
+	}
+	now := time.Now()
+	user.ID = s.data.newID("users")
+	user.CreatedAt, user.UpdatedAt = now, now
+	s.data.users[user.ID] = *user
+	return nil
+}
+
+func (s *memoryUserStore) UpdateUser(ctx context.Context, user *models.User) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if _, exists := s.data.users[user.ID]; !exists {
+		return ErrNotFound
+	}
+	if s.conflicts(user) {
+		return ErrDuplicate
+	}
+	user.UpdatedAt = time.Now()
+	s.data.users[user.ID] = *user
+	return nil
+}
+
+// update applique une modification partielle à un utilisateur existant
+func (s *memoryUserStore) update(id uint, apply func(user *models.User)) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	user, exists := s.data.users[id]
+	if !exists {
+		return ErrNotFound
+	}
+	apply(&user)
+	user.UpdatedAt = time.Now()
+	s.data.users[id] = user
+	return nil
+}
+
+func (s *memoryUserStore) SetPassword(ctx context.Context, id uint, hash string) error {
+	return s.update(id, func(user *models.User) { user.Password = hash })
+}
+
+func (s *memoryUserStore) SetLoginFailures(ctx context.Context, id uint, attempts int, lockedUntil *time.Time) error {
+	return s.update(id, func(user *models.User) {
+		user.FailedLoginAttempts = attempts
+		user.LockedUntil = lockedUntil
+	})
+}
+
+func (s *memoryUserStore) SetDisabled(ctx context.Context, id uint, disabledAt *time.Time) error {
+	if err := s.update(id, func(user *models.User) { user.DisabledAt = disabledAt }); err != nil || disabledAt == nil {
+		return err
+	}
+	_, err := (&memorySessionStore{s.data}).DeleteUserSessions(ctx, id)
+	return err
+}
+
+func (s *memoryUserStore) DeleteUser(ctx context.Context, id uint) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	delete(s.data.users, id)
+	for taskID, task := range s.data.tasks {
+		if task.UserID == id {
+			delete(s.data.tasks, taskID)
+		}
+	}
+	for sessionID, session := range s.data.sessions {
+		if session.UserID == id {
+			delete(s.data.sessions, sessionID)
+		}
+	}
+	for identityID, identity := range s.data.identities {
+		if identity.UserID == id {
+			delete(s.data.identities, identityID)
+		}
+	}
+	for linkID, link := range s.data.magicLinks {
+		if link.UserID == id {
+			delete(s.data.magicLinks, linkID)
+		}
+	}
+	return nil
+}
+
+type memorySessionStore struct{ data *memoryData }
+
+func (s *memorySessionStore) CountActiveSessions(ctx context.Context, now time.Time) (int64, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	var count int64
+	for _, session := range s.data.sessions {
+		if session.ExpiresAt.After(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memorySessionStore) GetSession(ctx context.Context, token string) (*models.Session, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	for _, session := range s.data.sessions {
+		if session.Token == token {
+			return &session, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memorySessionStore) ReplaceSession(ctx context.Context, session *models.Session) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	for id, existing := range s.data.sessions {
+		if existing.UserID == session.UserID {
+			delete(s.data.sessions, id)
+		}
+	}
+	now := time.Now()
+	session.ID = s.data.newID("sessions")
+	session.CreatedAt, session.UpdatedAt = now, now
+	s.data.sessions[session.ID] = *session
+	return nil
+}
+
+func (s *memorySessionStore) DeleteSession(ctx context.Context, id uint) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	delete(s.data.sessions, id)
+	return nil
+}
+
+func (s *memorySessionStore) DeleteUserSessions(ctx context.Context, userID uint) (int64, error) {
+	return s.deleteWhere(func(session models.Session) bool { return session.UserID == userID }), nil
+}
+
+func (s *memorySessionStore) DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	return s.deleteWhere(func(session models.Session) bool { return !session.ExpiresAt.After(now) }), nil
+}
+
+func (s *memorySessionStore) DeleteAllSessions(ctx context.Context) (int64, error) {
+	return s.deleteWhere(func(models.Session) bool { return true }), nil
+}
+
+// deleteWhere supprime les sessions retenues par match et renvoie leur nombre
+func (s *memorySessionStore) deleteWhere(match func(session models.Session) bool) int64 {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	var deleted int64
+	for id, session := range s.data.sessions {
+		if match(session) {
+			delete(s.data.sessions, id)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+type memoryIdentityStore struct{ data *memoryData }
+
+func (s *memoryIdentityStore) GetIdentity(ctx context.Context, provider, subject string) (*models.ExternalIdentity, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	for _, identity := range s.data.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			identity.User = s.data.users[identity.UserID]
+			return &identity, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryIdentityStore) CreateIdentity(ctx context.Context, identity *models.ExternalIdentity) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if _, exists := s.data.users[identity.UserID]; !exists {
+		return ErrNotFound
+	}
+	for _, existing := range s.data.identities {
+		if existing.Provider == identity.Provider && existing.Subject == identity.Subject {
+			return ErrDuplicate
+		}
+	}
+	now := time.Now()
+	identity.ID = s.data.newID("external_identities")
+	identity.CreatedAt, identity.UpdatedAt = now, now
+	stored := *identity
+	stored.User = models.User{}
+	s.data.identities[identity.ID] = stored
+	return nil
+}
+
+type memoryMagicLinkStore struct{ data *memoryData }
+
+func (s *memoryMagicLinkStore) GetMagicLink(ctx context.Context, tokenHash string) (*models.MagicLink, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	for _, link := range s.data.magicLinks {
+		if link.TokenHash == tokenHash {
+			link.User = s.data.users[link.UserID]
+			return &link, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryMagicLinkStore) ReplaceMagicLink(ctx context.Context, link *models.MagicLink) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	for id, existing := range s.data.magicLinks {
+		if existing.UserID == link.UserID && existing.UsedAt == nil {
+			delete(s.data.magicLinks, id)
+		}
+	}
+	link.ID = s.data.newID("magic_links")
+	link.CreatedAt = time.Now()
+	stored := *link
+	stored.User = models.User{}
+	s.data.magicLinks[link.ID] = stored
+	return nil
+}
+
+func (s *memoryMagicLinkStore) ConsumeMagicLink(ctx context.Context, id uint, now time.Time) (bool, error) {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	link, exists := s.data.magicLinks[id]
+	if !exists || link.UsedAt != nil || !link.ExpiresAt.After(now) {
+		return false, nil
+	}
+	link.UsedAt = &now
+	s.data.magicLinks[id] = link
+	return true, nil
+}
+
+type memoryJobStore struct{ data *memoryData }
+
+func (s *memoryJobStore) AcquireJobLock(ctx context.Context, name, owner string, now, until time.Time) (bool, error) {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if lock, exists := s.data.jobLocks[name]; exists && lock.Owner != owner && lock.LockedUntil.After(now) {
+		return false, nil
+	}
+	s.data.jobLocks[name] = models.JobLock{Name: name, Owner: owner, LockedUntil: until}
+	return true, nil
+}
+
+func (s *memoryJobStore) ReleaseJobLock(ctx context.Context, name, owner string, until time.Time) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if lock, exists := s.data.jobLocks[name]; exists && lock.Owner == owner {
+		lock.LockedUntil = until
+		s.data.jobLocks[name] = lock
+	}
+	return nil
+}
+
+func (s *memoryJobStore) CreateJobRun(ctx context.Context, run *models.JobRun) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	run.ID = s.data.newID("job_runs")
+	s.data.jobRuns[run.ID] = *run
+	return nil
+}
+
+func (s *memoryJobStore) UpdateJobRun(ctx context.Context, run *models.JobRun) error {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	if _, exists := s.data.jobRuns[run.ID]; !exists {
+		return ErrNotFound
+	}
+	s.data.jobRuns[run.ID] = *run
+	return nil
+}
+
+func (s *memoryJobStore) ListJobRuns(ctx context.Context, job string, limit int) ([]models.JobRun, error) {
+	s.data.mu.RLock()
+	defer s.data.mu.RUnlock()
+
+	runs := []models.JobRun{}
+	ids := sortedIDs(s.data.jobRuns)
+	for i := len(ids) - 1; i >= 0 && len(runs) < limit; i-- {
+		if run := s.data.jobRuns[ids[i]]; job == "" || run.Job == job {
+			runs = append(runs, run)
+		}
+	}
+	return runs, nil
+}
+
+func (s *memoryJobStore) PruneJobRuns(ctx context.Context, job string, keep int) (int64, error) {
+	s.data.mu.Lock()
+	defer s.data.mu.Unlock()
+
+	var kept, pruned int64
+	ids := sortedIDs(s.data.jobRuns)
+	for i := len(ids) - 1; i >= 0; i-- {
+		if s.data.jobRuns[ids[i]].Job != job {
+			continue
+		}
+		if kept < int64(keep) {
+			kept++
+			continue
+		}
+		delete(s.data.jobRuns, ids[i])
+		pruned++
+	}
+	return pruned, nil
+}
+
+// memoryHealthStore est toujours disponible : les données sont dans le processus et sans schéma
+type memoryHealthStore struct{}
+
+func (memoryHealthStore) Ping(ctx context.Context) error { return nil }
+
+func (memoryHealthStore) CheckSchema(ctx context.Context) error { return nil }
@@ -0,0 +1,130 @@
+// Package store définit l'accès aux données de l'API, indépendamment du moteur de stockage.
+// Deux implémentations sont fournies : GORM (SQLite, PostgreSQL, MySQL) et mémoire (tests, démonstrations)
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+	"to-do-list-api/models"
+)
+
+var (
+	// ErrNotFound est renvoyée lorsque l'enregistrement demandé n'existe pas
+	ErrNotFound = errors.New("enregistrement introuvable")
+	// ErrDuplicate est renvoyée lorsqu'une contrainte d'unicité est violée
+	ErrDuplicate = errors.New("enregistrement déjà existant")
+)
+
+// Store regroupe les dépôts injectés dans les contrôleurs et middlewares
+type Store struct {
+	Tasks      TaskStore
+	Users      UserStore
+	Sessions   SessionStore
+	Identities IdentityStore
+	MagicLinks MagicLinkStore
+	Jobs       JobStore
+	Health     HealthStore
+}
+
+// HealthStore vérifie que le stockage est utilisable (sonde de disponibilité)
+type HealthStore interface {
+	// Ping vérifie que la base de données répond
+	Ping(ctx context.Context) error
+	// CheckSchema vérifie que le schéma est à la version attendue par le binaire
+	CheckSchema(ctx context.Context) error
+}
+
+// TaskFilter restreint la liste des tâches ; les champs vides ne filtrent pas
+type TaskFilter struct {
+	UserID uint
+	Status string
+	Tag    string // étiquette que la tâche doit porter
+}
+
+// TaskStore gère les tâches
+type TaskStore interface {
+	ListTasks(ctx context.Context, filter TaskFilter) ([]models.Task, error)
+	GetTask(ctx context.Context, id uint) (*models.Task, error)
+	CreateTask(ctx context.Context, task *models.Task) error
+	UpdateTask(ctx context.Context, task *models.Task) error
+	// DeleteTask met la tâche à la corbeille : elle n'est plus renvoyée, puis PurgeDeletedTasks la supprime définitivement
+	DeleteTask(ctx context.Context, id uint) error
+	// PurgeDeletedTasks supprime définitivement les tâches mises à la corbeille avant before et renvoie leur nombre
+	PurgeDeletedTasks(ctx context.Context, before time.Time) (int64, error)
+	// ListDueReminders renvoie, avec leur utilisateur chargé, au plus limit tâches non terminées dont le rappel est échu
+	// à la date now, n'a pas encore été envoyé et a échoué moins de maxAttempts fois. Les rappels déjà en échec
+	// passent en dernier
+	ListDueReminders(ctx context.Context, now time.Time, maxAttempts, limit int) ([]models.Task, error)
+	// MarkReminded enregistre l'envoi du rappel de la tâche
+	MarkReminded(ctx context.Context, id uint, at time.Time) error
+	// RecordReminderFailure compte un envoi en échec du rappel de la tâche
+	RecordReminderFailure(ctx context.Context, id uint) error
+	// CountTasksByStatus renvoie le nombre de tâches de chaque statut, tous utilisateurs confondus
+	CountTasksByStatus(ctx context.Context) (map[string]int64, error)
+}
+
+// UserStore gère les comptes utilisateurs. Les emails sont attendus sous forme normalisée
+// (pkg.NormalizeEmail) ; les usernames sont comparés sans tenir compte de la casse
+type UserStore interface {
+	ListUsers(ctx context.Context) ([]models.User, error)
+	GetUser(ctx context.Context, id uint) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	CreateUser(ctx context.Context, user *models.User) error
+	UpdateUser(ctx context.Context, user *models.User) error
+	SetPassword(ctx context.Context, id uint, hash string) error
+	SetLoginFailures(ctx context.Context, id uint, attempts int, lockedUntil *time.Time) error
+	// SetDisabled désactive le compte à la date disabledAt en fermant ses sessions, ou le réactive si disabledAt est nil
+	SetDisabled(ctx context.Context, id uint, disabledAt *time.Time) error
+	// DeleteUser supprime le compte ainsi que ses tâches, sessions, identités externes et liens de connexion
+	DeleteUser(ctx context.Context, id uint) error
+}
+
+// SessionStore gère les sessions (une seule par utilisateur)
+type SessionStore interface {
+	GetSession(ctx context.Context, token string) (*models.Session, error)
+	// ReplaceSession enregistre la session en supprimant l'éventuelle session précédente de l'utilisateur
+	ReplaceSession(ctx context.Context, session *models.Session) error
+	DeleteSession(ctx context.Context, id uint) error
+	// DeleteUserSessions ferme les sessions d'un utilisateur et renvoie leur nombre
+	DeleteUserSessions(ctx context.Context, userID uint) (int64, error)
+	// DeleteExpiredSessions supprime les sessions expirées à la date now et renvoie leur nombre
+	DeleteExpiredSessions(ctx context.Context, now time.Time) (int64, error)
+	// DeleteAllSessions ferme toutes les sessions et renvoie leur nombre
+	DeleteAllSessions(ctx context.Context) (int64, error)
+	// CountActiveSessions renvoie le nombre de sessions non expirées à la date now
+	CountActiveSessions(ctx context.Context, now time.Time) (int64, error)
+}
+
+// IdentityStore gère les identités externes (OpenID Connect)
+type IdentityStore interface {
+	// GetIdentity renvoie l'identité avec son utilisateur chargé
+	GetIdentity(ctx context.Context, provider, subject string) (*models.ExternalIdentity, error)
+	CreateIdentity(ctx context.Context, identity *models.ExternalIdentity) error
+}
+
+// MagicLinkStore gère les liens de connexion sans mot de passe
+type MagicLinkStore interface {
+	// GetMagicLink renvoie le lien avec son utilisateur chargé
+	GetMagicLink(ctx context.Context, tokenHash string) (*models.MagicLink, error)
+	// ReplaceMagicLink enregistre le lien en supprimant les liens non utilisés de l'utilisateur
+	ReplaceMagicLink(ctx context.Context, link *models.MagicLink) error
+	// ConsumeMagicLink marque le lien comme utilisé s'il est encore valide ; seule la première consommation renvoie true
+	ConsumeMagicLink(ctx context.Context, id uint, now time.Time) (bool, error)
+}
+
+// JobStore conserve l'historique des tâches de fond et les verrous qui empêchent deux instances de les exécuter en même
+// temps
+type JobStore interface {
+	// AcquireJobLock réserve la tâche name à owner jusqu'à until, si aucun autre propriétaire ne la détient à la date now
+	AcquireJobLock(ctx context.Context, name, owner string, now, until time.Time) (bool, error)
+	// ReleaseJobLock ramène à until l'expiration du verrou de la tâche name, si owner le détient
+	ReleaseJobLock(ctx context.Context, name, owner string, until time.Time) error
+	CreateJobRun(ctx context.Context, run *models.JobRun) error
+	UpdateJobRun(ctx context.Context, run *models.JobRun) error
+	// ListJobRuns renvoie les limit dernières exécutions, de la plus récente à la plus ancienne ; job vide : toutes les tâches
+	ListJobRuns(ctx context.Context, job string, limit int) ([]models.JobRun, error)
+	// PruneJobRuns ne conserve que les keep dernières exécutions de la tâche job et renvoie le nombre d'exécutions supprimées
+	PruneJobRuns(ctx context.Context, job string, keep int) (int64, error)
+}
@@ -0,0 +1,93 @@
+// Package tracing instrumente l'API avec OpenTelemetry : une trace par requête HTTP, un span par middleware
+// et contrôleur, et un span par requête SQL. Le contexte de trace W3C (traceparent) est propagé
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exportateurs de traces pris en charge
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Nom de l'instrumentation, porté par chaque span
+const instrumentationName = "to-do-list-api"
+
+// Options règle l'export des traces
+type Options struct {
+	Exporter    string  // none, stdout ou otlp
+	Endpoint    string  // hôte:port du collecteur OTLP/HTTP ; vide : variables OTEL_EXPORTER_OTLP_*
+	Insecure    bool    // collecteur OTLP en HTTP plutôt qu'en HTTPS
+	SampleRatio float64 // proportion des traces conservées, entre 0 et 1
+	ServiceName string
+	Version     string
+}
+
+// Tracer crée les spans de l'API. Tant que Setup n'a pas été appelée, le fournisseur global
+// ne fait rien et l'instrumentation n'a pas de coût notable
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installe le fournisseur de traces et la propagation W3C (traceparent, baggage).
+// La fonction renvoyée vide les spans en attente ; elle est appelée à l'arrêt du serveur
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if opts.Exporter == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+		semconv.ServiceVersion(opts.Version),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(2*time.Second)),
+		sdktrace.WithResource(res),
+		//Une requête dont l'appelant a déjà décidé de l'échantillonnage suit sa décision
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// newExporter crée l'exportateur choisi par la configuration
+func newExporter(ctx context.Context, opts Options) (sdktrace.SpanExporter, error) {
+	switch opts.Exporter {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, options...)
+	}
+	return nil, fmt.Errorf("exportateur de traces inconnu : %q (none, stdout ou otlp)", opts.Exporter)
+}